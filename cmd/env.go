@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"lazycurl/internal/env"
+
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage environments and their variables",
+	Long: `Environments hold variables that are substituted into {{var}} references
+in a request's URL, headers and body when it runs.`,
+}
+
+var envListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List environments and their variables",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		set := mustLoadEnvironments()
+		if len(set.Environments) == 0 {
+			fmt.Println("No environments. Create one with: lazycurl env set NAME KEY=VALUE")
+			return
+		}
+		for _, e := range set.Environments {
+			marker := "  "
+			if e.Name == set.Active {
+				marker = "* "
+			}
+			fmt.Printf("%s%s\n", marker, e.Name)
+
+			keys := make([]string, 0, len(e.Vars))
+			for k := range e.Vars {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Printf("    %s=%s\n", k, e.Vars[k])
+			}
+		}
+	},
+}
+
+var envUseCmd = &cobra.Command{
+	Use:   "use NAME",
+	Short: "Make an environment active (\"none\" to deactivate)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		set := mustLoadEnvironments()
+		name := args[0]
+		if name == "none" {
+			name = ""
+		} else if set.Get(name) == nil {
+			fmt.Printf("Error: no environment named %q\n", name)
+			os.Exit(1)
+		}
+		set.Active = name
+		mustSaveEnvironments(set)
+	},
+}
+
+var envSetCmd = &cobra.Command{
+	Use:   "set NAME KEY=VALUE...",
+	Short: "Set variables in an environment, creating it if needed",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		set := mustLoadEnvironments()
+		e := set.Upsert(args[0])
+		for _, kv := range args[1:] {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || k == "" {
+				fmt.Printf("Error: expected KEY=VALUE, got %q\n", kv)
+				os.Exit(1)
+			}
+			e.Vars[k] = v
+		}
+		mustSaveEnvironments(set)
+	},
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:   "unset NAME KEY...",
+	Short: "Remove variables from an environment",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		set := mustLoadEnvironments()
+		e := set.Get(args[0])
+		if e == nil {
+			fmt.Printf("Error: no environment named %q\n", args[0])
+			os.Exit(1)
+		}
+		for _, k := range args[1:] {
+			delete(e.Vars, k)
+		}
+		mustSaveEnvironments(set)
+	},
+}
+
+var envRmCmd = &cobra.Command{
+	Use:   "rm NAME",
+	Short: "Delete an environment",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		set := mustLoadEnvironments()
+		if !set.Remove(args[0]) {
+			fmt.Printf("Error: no environment named %q\n", args[0])
+			os.Exit(1)
+		}
+		mustSaveEnvironments(set)
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envListCmd, envUseCmd, envSetCmd, envUnsetCmd, envRmCmd)
+	rootCmd.AddCommand(envCmd)
+}
+
+func mustLoadEnvironments() *env.Set {
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	set, err := ws.LoadEnvironments()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return set
+}
+
+func mustSaveEnvironments(set *env.Set) {
+	ws, err := openWorkspace()
+	if err == nil {
+		err = ws.SaveEnvironments(set)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"lazycurl/internal/postman"
+
+	"github.com/spf13/cobra"
+)
+
+var importEnvironments []string
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import requests from other tools",
+}
+
+var importPostmanCmd = &cobra.Command{
+	Use:   "postman COLLECTION.json",
+	Short: "Import a Postman Collection v2.1 (and environments) into the workspace",
+	Long: `Imports folders, requests, headers, bodies, auth and variables from a Postman
+Collection v2.1 export. Collection variables become an environment named after the
+collection; each --environment file becomes an environment of its own.
+Scripts and other unsupported items are listed in the summary.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		result, err := postman.ImportFiles(args[0], importEnvironments)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ws, err := openWorkspace()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		reqs, err := ws.LoadRequests()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		envs, err := ws.LoadEnvironments()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		reqs = result.MergeInto(reqs, envs)
+		if err := ws.SaveRequests(reqs); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ws.SaveEnvironments(envs); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(result.Summary())
+		fmt.Printf("Workspace: %s\n", ws.Dir)
+	},
+}
+
+func init() {
+	importPostmanCmd.Flags().StringArrayVarP(&importEnvironments, "environment", "e", nil, "Postman environment file to import (repeatable)")
+	importCmd.AddCommand(importPostmanCmd)
+	rootCmd.AddCommand(importCmd)
+}
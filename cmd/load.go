@@ -0,0 +1,484 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"lazycurl/internal/load"
+	"lazycurl/internal/model"
+	"lazycurl/internal/runs"
+	"lazycurl/internal/transport"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	loadEnv         string
+	loadRequest     requestFlags
+	loadConcurrency int
+	loadDuration    time.Duration
+	loadCount       int
+	loadRPS         float64
+	loadStages      string
+	loadReport      string
+	loadInterval    time.Duration
+	loadThresholds  []string
+	loadAbortOnFail bool
+	loadDataFile    string
+	loadDataOrder   string
+	loadDataEnd     string
+	loadScenario    string
+	loadSamples     string
+	loadPerSecond   string
+	loadPrometheus  string
+)
+
+// exitThresholdsFailed is the exit status when a threshold fails, the same as k6's.
+const exitThresholdsFailed = 99
+
+var loadCmd = &cobra.Command{
+	Use:   "load [url]",
+	Short: "Run a load test without the TUI",
+	Long: `Run a load test against a URL or a request saved in the workspace (--request).
+
+Progress is printed to stderr every --interval; the final report is written as JSON
+to stdout, or to the file given with --report. The test ends after --duration, or
+once --requests requests were sent, whichever comes first. Ctrl+C stops it early
+and still writes the report.
+
+By default every worker sends its next request as soon as the last one returns, so
+a slow server also slows the test down. With --rps requests start at a fixed rate
+instead, and --concurrency caps how many may be in flight; iterations that find
+every worker busy are dropped, and both dropped and late ones are reported.
+
+--stages shapes the load over time instead, replacing --concurrency and --duration:
+'30s:50, 2m:50, 10s:200, 30s:0' ramps up to 50 workers over 30s, holds them for 2m,
+spikes to 200 and ramps down. Each stage moves linearly from the previous target
+(zero for the first). Targets with an rps suffix ('1m:100rps') set the arrival rate
+instead, with --concurrency as the most in flight. The report breaks results down
+per stage.
+
+--data-file feeds each iteration from a data file: a CSV file with a header row, or
+JSONL (one object per line). Each row's columns fill the request's {{variables}},
+taking precedence over the environment. --data-strategy picks the rows in file order
+(sequential), at random, or unique per worker, so no two workers share one; once every
+row was used --data-end recycles them or stops the test.
+
+--scenario tests several saved requests at once. A mix such as
+'List users=70, Get user=20, Create user=10' sends one request per iteration, picked
+by weight (1 when left out). A flow such as 'Login -> List users=2s -> Get user'
+sends them all in order per iteration, like a user would, pausing for the think
+time given after a step; --requests then counts flows. The summary and report break
+results down per request.
+
+To feed other dashboards, --samples writes every request (start, request, status,
+latency, bytes and error) as CSV, or JSON lines for a .jsonl file; --per-second writes
+the per-second windows as CSV; --prometheus writes a snapshot of the results in the
+Prometheus text format, e.g. for a Pushgateway.
+
+Thresholds such as --threshold 'p95<300ms' --threshold 'error_rate<1%' gate the
+result: if any fails, the failures are listed and the exit status is 99.
+Metrics: pNN (e.g. p50, p99.9), avg, min, max, error_rate and rps.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if loadConcurrency <= 0 {
+			fatalf("--concurrency must be at least 1")
+		}
+		if loadRPS < 0 {
+			fatalf("--rps must not be negative")
+		}
+		var profile load.Profile
+		var err error
+		if loadStages != "" {
+			if loadRPS > 0 {
+				fatalf("--rps and --stages can't be combined; give rates in the stages, e.g. 30s:100rps")
+			}
+			if profile, err = load.ParseProfile(loadStages); err != nil {
+				fatalf("%v", err)
+			}
+		} else if loadDuration <= 0 && loadCount <= 0 {
+			fatalf("give a --duration or a number of --requests")
+		}
+
+		var thresholds []load.Threshold
+		for _, s := range loadThresholds {
+			ts, err := load.ParseThresholds(s)
+			if err != nil {
+				fatalf("%v", err)
+			}
+			thresholds = append(thresholds, ts...)
+		}
+
+		var feeder *load.Feeder
+		if loadDataFile != "" {
+			strategy, err := load.ParseFeedStrategy(loadDataOrder)
+			if err != nil {
+				fatalf("%v", err)
+			}
+			end, err := load.ParseFeedEnd(loadDataEnd)
+			if err != nil {
+				fatalf("%v", err)
+			}
+			if feeder, err = load.LoadFeeder(loadDataFile, strategy, end); err != nil {
+				fatalf("%v", err)
+			}
+		}
+
+		var fed []string
+		if feeder != nil {
+			fed = feeder.Columns
+		}
+		var req model.Request
+		var scenario load.Scenario
+		target := ""
+		if loadScenario != "" {
+			if len(args) > 0 || loadRequest.saved != "" {
+				fatalf("--scenario can't be combined with a URL or --request")
+			}
+			scenario, err = load.ParseScenario(loadScenario, func(nameOrID string) (model.Request, error) {
+				req, err := findSavedRequest(nameOrID)
+				if err != nil {
+					return req, err
+				}
+				if loadRequest.backend != "" {
+					req.Backend = loadRequest.backend
+				}
+				if _, err := transport.New(req.Backend); err != nil {
+					return req, err
+				}
+				return resolveRequest(req, loadEnv, fed...), nil
+			})
+			if err != nil {
+				fatalf("%v", err)
+			}
+			if len(scenario.Steps) == 0 {
+				fatalf("--scenario names no requests")
+			}
+			target = scenario.Describe()
+		} else {
+			if req, err = buildRequest(cmd, args, loadRequest); err != nil {
+				fatalf("%v", err)
+			}
+			if _, err := transport.New(req.Backend); err != nil {
+				fatalf("%v", err)
+			}
+			req = resolveRequest(req, loadEnv, fed...)
+			target = req.Method + " " + req.URL
+		}
+
+		// The duration only applies when given explicitly alongside a request count
+		duration := loadDuration
+		if loadCount > 0 && !cmd.Flags().Changed("duration") {
+			duration = 0
+		}
+
+		switch {
+		case len(profile.Stages) > 0 && profile.Rate:
+			fmt.Fprintf(os.Stderr, "Load testing %s in %d stages up to %g rps, at most %d in flight",
+				target, len(profile.Stages), profile.Max(), loadConcurrency)
+			duration = profile.Duration()
+		case len(profile.Stages) > 0:
+			fmt.Fprintf(os.Stderr, "Load testing %s in %d stages up to %g workers",
+				target, len(profile.Stages), profile.Max())
+			duration = profile.Duration()
+		case loadRPS > 0:
+			fmt.Fprintf(os.Stderr, "Load testing %s at %g rps, at most %d in flight", target, loadRPS, loadConcurrency)
+		default:
+			fmt.Fprintf(os.Stderr, "Load testing %s with %d workers", target, loadConcurrency)
+		}
+		if duration > 0 {
+			fmt.Fprintf(os.Stderr, " for %s", duration)
+		}
+		if loadCount > 0 && scenario.Flow {
+			fmt.Fprintf(os.Stderr, ", %d flows", loadCount)
+		} else if loadCount > 0 {
+			fmt.Fprintf(os.Stderr, ", %d requests", loadCount)
+		}
+		if feeder != nil {
+			fmt.Fprintf(os.Stderr, ", %d rows of %s (%s)", feeder.Len(), feeder.Name, load.DescribeFeed(feeder.Strategy, feeder.End))
+		}
+		fmt.Fprintln(os.Stderr)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		runner := load.NewRunner()
+		runner.MaxRequests = loadCount
+		runner.RPS = loadRPS
+		runner.Profile = profile
+		runner.Thresholds = thresholds
+		runner.AbortOnFail = loadAbortOnFail
+		runner.Feeder = feeder
+		runner.Scenario = scenario
+		if loadSamples != "" {
+			if runner.Samples, err = load.NewSampleLog(); err != nil {
+				fatalf("%v", err)
+			}
+		}
+
+		// Exiting skips deferred calls, so everything up to the exit status runs in
+		// a function of its own that removes the samples file first
+		stats, err := func() (*load.Stats, error) {
+			if runner.Samples != nil {
+				defer runner.Samples.Close()
+			}
+
+			var stats *load.Stats
+			start := time.Now()
+			lastPrint := start
+			for msg := range runner.Run(ctx, req, loadConcurrency, duration) {
+				stats = msg.Stats
+				if !msg.Done && time.Since(lastPrint) >= loadInterval {
+					lastPrint = time.Now()
+					printLoadProgress(stats)
+				}
+			}
+			printLoadSummary(stats)
+			config := runs.Config{
+				Concurrency: loadConcurrency,
+				Duration:    duration,
+				MaxRequests: loadCount,
+				RPS:         loadRPS,
+				Stages:      loadStages,
+				Thresholds:  thresholdNames(thresholds),
+				AbortOnFail: loadAbortOnFail,
+				Scenario:    loadScenario,
+			}
+			if feeder != nil {
+				config.DataFile, config.DataStrategy, config.DataEnd = loadDataFile, feeder.Strategy, feeder.End
+			}
+			recordRun(start, req, scenario.Steps, config, stats)
+
+			report := load.NewReport(stats)
+			if err := writeLoadReport(report); err != nil {
+				return nil, err
+			}
+			return stats, exportLoad(runner.Samples, report)
+		}()
+		if err != nil {
+			fatalf("%v", err)
+		}
+		if failed := stats.FailedThresholds(); len(failed) > 0 {
+			fmt.Fprintf(os.Stderr, "%d of %d thresholds failed\n", len(failed), len(stats.Thresholds))
+			os.Exit(exitThresholdsFailed)
+		}
+	},
+}
+
+func init() {
+	addRequestFlags(loadCmd, &loadRequest)
+	loadCmd.Flags().StringVar(&loadEnv, "env", "", "environment to resolve {{var}} references with (default: the active one)")
+	loadCmd.Flags().IntVarP(&loadConcurrency, "concurrency", "c", 10, "number of concurrent workers (with --rps, the most requests in flight)")
+	loadCmd.Flags().Float64Var(&loadRPS, "rps", 0, "start requests at this rate instead of back to back (0 = as fast as the workers go)")
+	loadCmd.Flags().StringVar(&loadStages, "stages", "", "load profile as DURATION:TARGET stages, e.g. '30s:50, 2m:50, 30s:0' (workers) or '1m:100rps'")
+	loadCmd.Flags().DurationVar(&loadDuration, "duration", 10*time.Second, "how long to send requests for")
+	loadCmd.Flags().IntVarP(&loadCount, "requests", "n", 0, "stop after sending this many requests (0 = no limit)")
+	loadCmd.Flags().StringVar(&loadReport, "report", "", "write the JSON report to this file instead of stdout")
+	loadCmd.Flags().StringArrayVar(&loadThresholds, "threshold", nil, "pass/fail criterion, e.g. 'p95<300ms' or 'error_rate<1%' (repeatable)")
+	loadCmd.Flags().BoolVar(&loadAbortOnFail, "abort-on-fail", false, "stop the test as soon as a threshold can no longer pass")
+	loadCmd.Flags().StringVar(&loadDataFile, "data-file", "", "CSV or JSONL file whose rows fill {{variables}}, one row per iteration")
+	loadCmd.Flags().StringVar(&loadDataOrder, "data-strategy", "sequential", "how rows are picked: sequential, random or unique (per worker)")
+	loadCmd.Flags().StringVar(&loadDataEnd, "data-end", "recycle", "once every row was used: recycle, or stop the test")
+	loadCmd.Flags().StringVar(&loadScenario, "scenario", "", "saved requests to send instead of one: a mix 'List=70, Get=30' or a flow 'Login -> List=2s'")
+	loadCmd.Flags().StringVar(&loadSamples, "samples", "", "write every request to this file: CSV, or JSON lines for a .jsonl file")
+	loadCmd.Flags().StringVar(&loadPerSecond, "per-second", "", "write the per-second windows to this CSV file")
+	loadCmd.Flags().StringVar(&loadPrometheus, "prometheus", "", "write a snapshot of the results to this file in the Prometheus text format")
+	loadCmd.Flags().DurationVar(&loadInterval, "interval", time.Second, "how often to print progress to stderr")
+	rootCmd.AddCommand(loadCmd)
+}
+
+// recordRun saves the test in the workspace so it can be compared with others.
+// Failing to save is reported but never fails the test itself.
+func recordRun(start time.Time, req model.Request, steps []load.ScenarioStep, config runs.Config, s *load.Stats) {
+	id, err := saveRun(start, req, steps, config, s)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save run: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Saved run %s\n", id)
+}
+
+func saveRun(start time.Time, req model.Request, steps []load.ScenarioStep, config runs.Config, s *load.Stats) (string, error) {
+	ws, err := openWorkspace()
+	if err != nil {
+		return "", err
+	}
+	envs, err := ws.LoadEnvironments()
+	if err != nil {
+		return "", err
+	}
+	name := loadEnv
+	if name == "" {
+		name = envs.Active
+	}
+	var vars map[string]string
+	if e := envs.Get(name); e != nil {
+		vars = e.Vars
+	}
+	run := runs.NewRun(start, req, name, vars, config, s)
+	run.Scenario = steps
+	return run.ID, runs.NewStore(ws.Path(runs.Dir)).Save(run)
+}
+
+// thresholdNames returns the thresholds as written, one each.
+func thresholdNames(thresholds []load.Threshold) []string {
+	names := make([]string, len(thresholds))
+	for i, t := range thresholds {
+		names[i] = t.Raw
+	}
+	return names
+}
+
+// printLoadProgress prints a one-line snapshot of a running test.
+func printLoadProgress(s *load.Stats) {
+	line := fmt.Sprintf("%6s  reqs=%d  rps=%.1f  avg=%s  p95=%s  max=%s  failures=%d",
+		s.ElapsedTime.Round(time.Second), s.TotalRequests, s.RPS(),
+		s.AvgLatency.Round(time.Microsecond), s.P95().Round(time.Microsecond),
+		s.MaxLatency.Round(time.Microsecond), s.Failures())
+	if s.TargetRPS > 0 || s.Profile.Rate {
+		line += fmt.Sprintf("  dropped=%d  late=%d", s.Dropped, s.Late)
+	}
+	if len(s.Stages) > 0 {
+		line += fmt.Sprintf("  stage=%d/%d  target=%.1f %s  in_flight=%d",
+			s.Stage+1, len(s.Stages), s.StageTarget, s.Profile.Unit(), s.InFlight)
+	}
+	fmt.Fprintln(os.Stderr, line)
+}
+
+// printLoadSummary prints the final results for humans.
+func printLoadSummary(s *load.Stats) {
+	state := "Done"
+	if s.Aborted {
+		state = "Aborted"
+	}
+	fmt.Fprintf(os.Stderr, "\n%s: %d requests in %s (%.1f rps), %d cut off\n",
+		state, s.TotalRequests, s.ElapsedTime.Round(time.Millisecond), s.RPS(), s.CutOff)
+	if s.DataExhausted {
+		fmt.Fprintln(os.Stderr, "Stopped early: the data file ran out")
+	}
+	if s.TargetRPS > 0 {
+		fmt.Fprintf(os.Stderr, "Target: %g rps, %d dropped at the in-flight cap, %d started late\n", s.TargetRPS, s.Dropped, s.Late)
+	} else if s.Profile.Rate {
+		fmt.Fprintf(os.Stderr, "%d dropped at the in-flight cap, %d started late\n", s.Dropped, s.Late)
+	}
+	if s.TotalRequests > 0 {
+		fmt.Fprintf(os.Stderr, "Latency: min %s  p50 %s  p90 %s  p95 %s  p99 %s  p99.9 %s  max %s\n",
+			s.MinLatency.Round(time.Microsecond), s.Percentile(50).Round(time.Microsecond),
+			s.Percentile(90).Round(time.Microsecond), s.Percentile(95).Round(time.Microsecond),
+			s.Percentile(99).Round(time.Microsecond), s.Percentile(99.9).Round(time.Microsecond),
+			s.MaxLatency.Round(time.Microsecond))
+		fmt.Fprintf(os.Stderr, "         mean %s  stddev %s\n",
+			s.AvgLatency.Round(time.Microsecond), s.Latency.StdDev().Round(time.Microsecond))
+	}
+
+	fmt.Fprintf(os.Stderr, "Status codes: %s\n", formatStatusCodes(s.StatusCodes))
+
+	if len(s.Steps) > 0 {
+		fmt.Fprintln(os.Stderr, "Requests:")
+	}
+	for _, st := range s.Steps {
+		fmt.Fprintf(os.Stderr, "  %s: %d requests, p50 %s  p95 %s  p99 %s, %d failures, %s\n",
+			st.Name, st.Requests, st.Latency.Percentile(50).Round(time.Microsecond),
+			st.Latency.Percentile(95).Round(time.Microsecond), st.Latency.Percentile(99).Round(time.Microsecond),
+			st.Failures, formatStatusCodes(st.StatusCodes))
+	}
+
+	if len(s.Stages) > 0 {
+		fmt.Fprintln(os.Stderr, "Stages:")
+	}
+	for i, st := range s.Stages {
+		fmt.Fprintf(os.Stderr, "  %d. %s: %d requests (%.1f rps), p95 %s, %d errors\n",
+			i+1, s.Profile.Describe(i), st.Requests, s.StageRPS(i), s.StagePercentile(i, 95).Round(time.Microsecond), st.Errors)
+	}
+
+	if windows := s.Windows(); len(windows) > 1 {
+		rps := load.SummarizeWindows(windows, load.WindowStats.RPS)
+		p99 := load.SummarizeWindows(windows, func(w load.WindowStats) float64 { return float64(w.P99.Microseconds()) / 1000 })
+		fmt.Fprintf(os.Stderr, "Per second: rps min %.1f  mean %.1f  max %.1f | p99 min %.3fms  max %.3fms\n",
+			rps.Min, rps.Mean, rps.Max, p99.Min, p99.Max)
+	}
+	if s.Failures() > 0 {
+		fmt.Fprintf(os.Stderr, "Failures: %d (%.2f%%)\n", s.Failures(), 100*float64(s.Failures())/float64(s.TotalRequests))
+	}
+	for _, class := range load.ErrorClasses {
+		if c, ok := s.ErrorClasses[class]; ok {
+			fmt.Fprintf(os.Stderr, "  %s: %d, first at %s: %s\n", class, c.Count, c.FirstSeen.Round(time.Millisecond), c.Sample)
+		}
+	}
+
+	if len(s.Thresholds) > 0 {
+		fmt.Fprintln(os.Stderr, "Thresholds:")
+	}
+	for _, t := range s.Thresholds {
+		mark := "✓"
+		if !t.Pass {
+			mark = "✗"
+		}
+		fmt.Fprintf(os.Stderr, "  %s %s (actual %s)\n", mark, t.Threshold, t.FormatActual())
+	}
+	if s.AbortedBy != "" {
+		fmt.Fprintf(os.Stderr, "Stopped early: %s can no longer pass\n", s.AbortedBy)
+	}
+}
+
+// formatStatusCodes lists status codes with their counts, e.g. "200: 97, 500: 3".
+func formatStatusCodes(counts map[int]int) string {
+	codes := make([]int, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%d: %d", code, counts[code])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// exportLoad writes the exports asked for with --samples, --per-second and --prometheus.
+func exportLoad(samples *load.SampleLog, r load.Report) error {
+	exports := []struct {
+		path  string
+		write func(string) error
+	}{
+		{loadSamples, func(path string) error { return samples.WriteFile(path) }},
+		{loadPerSecond, func(path string) error { return load.WritePerSecondFile(path, r) }},
+		{loadPrometheus, func(path string) error { return load.WritePrometheusFile(path, r) }},
+	}
+	for _, e := range exports {
+		if e.path == "" {
+			continue
+		}
+		if err := e.write(e.path); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Exported %s\n", e.path)
+	}
+	return nil
+}
+
+// writeLoadReport writes the report to --report, or stdout.
+func writeLoadReport(r load.Report) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Keep thresholds like p95<300ms readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+	if loadReport == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(loadReport, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Report written to %s\n", loadReport)
+	return nil
+}
@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"os"
 
+	"lazycurl/internal/workspace"
+
 	"github.com/spf13/cobra"
 )
 
+var workspaceDir string
+
 var rootCmd = &cobra.Command{
 	Use:   "lazycurl",
 	Short: "A friendly TUI for curl",
@@ -17,6 +21,22 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&workspaceDir, "workspace", "", "workspace directory holding the request collection (default: user config dir)")
+}
+
+// openWorkspace opens the workspace selected by --workspace, or the default one.
+func openWorkspace() (*workspace.Workspace, error) {
+	dir := workspaceDir
+	if dir == "" {
+		var err error
+		if dir, err = workspace.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	return workspace.Open(dir)
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -1,38 +1,383 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"lazycurl/internal/curl"
+	"io"
+	"lazycurl/internal/env"
+	"lazycurl/internal/history"
+	"lazycurl/internal/jq"
 	"lazycurl/internal/model"
+	"lazycurl/internal/transport"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+// Output modes for run
+const (
+	outputText = "text" // Status, timing, headers and body for humans
+	outputJSON = "json" // The whole response as JSON
+	outputBody = "body" // Just the body, like curl
+)
+
+// exitHTTPError is the exit status for --fail on an HTTP error, the same as curl's.
+const exitHTTPError = 22
+
+var (
+	runEnv     string
+	runFilter  string
+	runRequest requestFlags
+	runOutput  string
+	runInclude bool
+	runFail    bool
+)
+
+// requestFlags describe the request to send, shared by run and load.
+type requestFlags struct {
+	method         string
+	headers        []string
+	data           string
+	json           string
+	dataBinary     string
+	dataURLEncode  []string
+	form           []string
+	saved          string
+	backend        string
+	timeout        time.Duration
+	connectTimeout time.Duration
+}
+
+// addRequestFlags registers the flags describing the request on cmd.
+func addRequestFlags(cmd *cobra.Command, f *requestFlags) {
+	cmd.Flags().StringVar(&f.backend, "backend", "", "transport backend (curl, native) (default: the request's, or curl)")
+	cmd.Flags().StringVarP(&f.method, "method", "X", "", "HTTP method (default: GET, or POST when a body is given)")
+	cmd.Flags().StringArrayVarP(&f.headers, "header", "H", nil, "request header as 'Name: value' (repeatable)")
+	cmd.Flags().StringVarP(&f.data, "data", "d", "", "request body; @file reads it from a file, @- from stdin")
+	cmd.Flags().StringVar(&f.json, "json", "", "JSON request body, sent as application/json; @file and @- as for --data")
+	cmd.Flags().StringVar(&f.dataBinary, "data-binary", "", "send the file at @file as the body, byte for byte")
+	cmd.Flags().StringArrayVar(&f.dataURLEncode, "data-urlencode", nil, "urlencoded form field as 'name=value' (repeatable)")
+	cmd.Flags().StringArrayVarP(&f.form, "form", "F", nil, "multipart form field as 'name=value', or 'name=@file' to attach a file (repeatable)")
+	cmd.Flags().StringVarP(&f.saved, "request", "r", "", "use a request saved in the workspace, by name or ID")
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 0, "maximum time for the whole request (0 = no limit)")
+	cmd.Flags().DurationVar(&f.connectTimeout, "connect-timeout", 0, "maximum time to establish the connection")
+}
+
 var runCmd = &cobra.Command{
 	Use:   "run [url]",
 	Short: "Run a single request",
-	Args:  cobra.ExactArgs(1),
+	Long: `Run a single request, given on the command line or saved in the workspace (--request).
+
+Flags override the saved request's method, URL, headers and body. The body can be
+given inline (-d 'text'), read from a file (-d @file.json) or from stdin (-d @-).
+--json does the same for a JSON body. --data-binary @file sends a file byte for byte,
+--data-urlencode fields make up a urlencoded form and -F fields a multipart one,
+where -F 'name=@file' attaches a file.
+
+Exits with status 1 when the request fails to complete, and with 22 when --fail is
+set and the response status is 400 or above.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		url := args[0]
-		req := model.NewRequest()
-		req.URL = url
+		if runOutput != outputText && runOutput != outputJSON && runOutput != outputBody {
+			fatalf("unknown output mode %q (want text, json or body)", runOutput)
+		}
 
-		executor := curl.NewExecutor()
-		fmt.Printf("Running GET %s...\n", url)
+		req, err := buildRequest(cmd, args, runRequest)
+		if err != nil {
+			fatalf("%v", err)
+		}
 
-		resp := executor.Execute(req)
+		executor, err := transport.New(req.Backend)
+		if err != nil {
+			fatalf("%v", err)
+		}
 
-		if resp.Error != nil {
-			fmt.Printf("Error: %v\n", resp.Error)
-			return
+		req = resolveRequest(req, runEnv)
+		if runOutput == outputText {
+			fmt.Fprintf(os.Stderr, "Running %s %s...\n", req.Method, req.URL)
 		}
 
-		fmt.Printf("Status: %d\n", resp.StatusCode)
-		fmt.Printf("Time: %s\n", resp.TimeTaken)
-		fmt.Printf("Body:\n%s\n", resp.Body)
+		// Ctrl+C aborts the request instead of killing the process mid-write
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		resp := executor.Execute(ctx, req)
+		recordHistory(req, resp)
+
+		body := resp.Body
+		if runFilter != "" && resp.Error == nil {
+			if body, err = jq.Apply(ctx, resp.Body, runFilter); err != nil {
+				fatalf("%v", err)
+			}
+		}
+
+		switch runOutput {
+		case outputJSON:
+			printResponseJSON(resp, body)
+		case outputBody:
+			if resp.Error == nil {
+				if runInclude {
+					printStatusAndHeaders(resp)
+					fmt.Println()
+				}
+				fmt.Print(body)
+				if runFilter != "" {
+					fmt.Println() // jq output has no trailing newline
+				}
+			}
+		default:
+			if resp.Error == nil {
+				printResponseText(resp, body)
+			}
+		}
+
+		if resp.Error != nil {
+			fatalf("%v", resp.Error)
+		}
+		if runFail && resp.StatusCode >= 400 {
+			fmt.Fprintf(os.Stderr, "Error: HTTP %d\n", resp.StatusCode)
+			os.Exit(exitHTTPError)
+		}
 	},
 }
 
 func init() {
+	runCmd.Flags().StringVar(&runEnv, "env", "", "environment to resolve {{var}} references with (default: the active one)")
+	runCmd.Flags().StringVar(&runFilter, "filter", "", "jq expression to pass the response body through, e.g. '.items[0].id'")
+	addRequestFlags(runCmd, &runRequest)
+	runCmd.Flags().StringVarP(&runOutput, "output", "o", outputText, "output mode: text, json (the whole response) or body")
+	runCmd.Flags().BoolVarP(&runInclude, "include", "i", false, "with --output body, print the status line and headers first")
+	runCmd.Flags().BoolVarP(&runFail, "fail", "f", false, "exit with status 22 when the response status is 400 or above")
 	rootCmd.AddCommand(runCmd)
 }
+
+// buildRequest assembles the request from a saved request (if any) and the flags.
+func buildRequest(cmd *cobra.Command, args []string, f requestFlags) (model.Request, error) {
+	req := model.NewRequest()
+	if f.saved != "" {
+		saved, err := findSavedRequest(f.saved)
+		if err != nil {
+			return req, err
+		}
+		req = saved
+	} else if len(args) == 0 {
+		return req, fmt.Errorf("give a URL or a saved request with --request")
+	}
+
+	if len(args) == 1 {
+		req.URL = args[0]
+	}
+	for _, h := range f.headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return req, fmt.Errorf("invalid header %q (want 'Name: value')", h)
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	if err := setBody(cmd, &req, f); err != nil {
+		return req, err
+	}
+	if f.method != "" {
+		req.Method = strings.ToUpper(f.method)
+	}
+	if f.backend != "" {
+		req.Backend = f.backend
+	}
+	if cmd.Flags().Changed("timeout") {
+		req.Timeout = f.timeout
+	}
+	if cmd.Flags().Changed("connect-timeout") {
+		req.ConnectTimeout = f.connectTimeout
+	}
+	return req, nil
+}
+
+// setBody sets the body of req from whichever body flag was given, if any.
+func setBody(cmd *cobra.Command, req *model.Request, f requestFlags) error {
+	var given []string
+	for _, name := range []string{"data", "json", "data-binary", "data-urlencode", "form"} {
+		if cmd.Flags().Changed(name) {
+			given = append(given, "--"+name)
+		}
+	}
+	switch len(given) {
+	case 0:
+		return nil
+	case 1:
+	default:
+		return fmt.Errorf("give only one body, not %s", strings.Join(given, " and "))
+	}
+
+	switch given[0] {
+	case "--data", "--json":
+		data, mode := f.data, model.BodyRaw
+		if given[0] == "--json" {
+			data, mode = f.json, model.BodyJSON
+		}
+		body, err := readData(data)
+		if err != nil {
+			return err
+		}
+		req.BodyMode, req.Body = mode, body
+	case "--data-binary":
+		path, ok := strings.CutPrefix(f.dataBinary, "@")
+		if !ok || path == "" || path == "-" {
+			return fmt.Errorf("--data-binary takes @file (use -d for inline text or stdin)")
+		}
+		req.BodyMode, req.BodyFile = model.BodyBinary, path
+	case "--data-urlencode", "--form":
+		req.BodyMode, req.Form = model.BodyURLEncoded, nil
+		fields := f.dataURLEncode
+		if given[0] == "--form" {
+			req.BodyMode, fields = model.BodyMultipart, f.form
+		}
+		for _, field := range fields {
+			name, value, ok := strings.Cut(field, "=")
+			if !ok || name == "" {
+				return fmt.Errorf("invalid form field %q (want 'name=value')", field)
+			}
+			ff := model.FormField{Name: name, Value: value}
+			if path, ok := strings.CutPrefix(value, "@"); ok && req.BodyMode == model.BodyMultipart {
+				ff.Value, ff.File = path, true
+			}
+			req.Form = append(req.Form, ff)
+		}
+	}
+	if !cmd.Flags().Changed("method") && f.saved == "" {
+		req.Method = http.MethodPost // Like curl -d
+	}
+	return nil
+}
+
+// resolveRequest resolves {{var}} references against the named environment, or the active one.
+// References to fed are left for a load test's data file.
+func resolveRequest(req model.Request, envName string, fed ...string) model.Request {
+	envs := mustLoadEnvironments()
+	if envName != "" {
+		if envs.Get(envName) == nil {
+			fatalf("no environment named %q", envName)
+		}
+		envs.Active = envName
+	}
+	req, missing := env.ResolveExcept(req, envs.ActiveVars(), fed)
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: undefined variables: %s\n", strings.Join(missing, ", "))
+	}
+	return req
+}
+
+// readData returns a body flag's value, reading @file or @- (stdin).
+func readData(data string) (string, error) {
+	path, ok := strings.CutPrefix(data, "@")
+	if !ok {
+		return data, nil
+	}
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read body: %v", err)
+	}
+	return string(b), nil
+}
+
+// findSavedRequest looks a request up in the workspace by ID or name.
+func findSavedRequest(nameOrID string) (model.Request, error) {
+	ws, err := openWorkspace()
+	if err != nil {
+		return model.Request{}, err
+	}
+	reqs, err := ws.LoadRequests()
+	if err != nil {
+		return model.Request{}, err
+	}
+	return model.FindRequest(reqs, nameOrID)
+}
+
+func printResponseText(resp model.Response, body string) {
+	fmt.Printf("Status: %d\n", resp.StatusCode)
+	fmt.Printf("Time: %s (dns %s, connect %s, tls %s, ttfb %s)\n", resp.TimeTaken,
+		resp.Timing.NameLookup, resp.Timing.Connect, resp.Timing.AppConnect, resp.Timing.StartTransfer)
+	fmt.Printf("Size: %d B | Remote: %s | HTTP/%s | Redirects: %d\n",
+		resp.SizeDownload, resp.RemoteIP, resp.HTTPVersion, resp.NumRedirects)
+	fmt.Printf("Headers:\n")
+	for _, k := range sortedHeaderKeys(resp.Headers) {
+		for _, v := range resp.Headers[k] {
+			fmt.Printf("  %s: %s\n", k, v)
+		}
+	}
+	if runFilter != "" {
+		fmt.Printf("Body (%s):\n%s\n", runFilter, body)
+	} else {
+		fmt.Printf("Body:\n%s\n", body)
+	}
+}
+
+// printStatusAndHeaders prints the response head the way curl -i does.
+func printStatusAndHeaders(resp model.Response) {
+	fmt.Printf("HTTP/%s %d %s\n", resp.HTTPVersion, resp.StatusCode, http.StatusText(resp.StatusCode))
+	for _, k := range sortedHeaderKeys(resp.Headers) {
+		for _, v := range resp.Headers[k] {
+			fmt.Printf("%s: %s\n", k, v)
+		}
+	}
+}
+
+// jsonResponse is model.Response as printed by --output json; the error becomes a string.
+type jsonResponse struct {
+	model.Response
+	Error string `json:"error,omitempty"`
+}
+
+func printResponseJSON(resp model.Response, body string) {
+	out := jsonResponse{Response: resp}
+	out.Body = body
+	if resp.Error != nil {
+		out.Error = resp.Error.Error()
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(out)
+}
+
+// fatalf reports an error on stderr and exits with status 1.
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+// recordHistory appends the execution to the workspace history.
+// Failing to record is reported but never fails the run itself.
+func recordHistory(req model.Request, resp model.Response) {
+	ws, err := openWorkspace()
+	if err == nil {
+		err = history.NewStore(ws.Path(history.File)).Append(history.NewEntry(req, resp))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record history: %v\n", err)
+	}
+}
+
+// sortedHeaderKeys returns the header names in a stable order for printing.
+func sortedHeaderKeys(headers http.Header) []string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
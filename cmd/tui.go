@@ -14,11 +14,34 @@ var tuiCmd = &cobra.Command{
 	Use:   "tui",
 	Short: "Start the terminal UI",
 	Run: func(cmd *cobra.Command, args []string) {
-		p := tea.NewProgram(tui.NewModel(), tea.WithAltScreen())
-		if _, err := p.Run(); err != nil {
+		ws, err := openWorkspace()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		m, err := tui.NewModel(ws)
+		if err != nil {
+			fmt.Printf("Failed to load workspace %s: %v\n", ws.Dir, err)
+			os.Exit(1)
+		}
+
+		p := tea.NewProgram(m, tea.WithAltScreen())
+		final, err := p.Run()
+		if err != nil {
 			fmt.Printf("Alas, there's been an error: %v", err)
 			os.Exit(1)
 		}
+		if fm, ok := final.(tui.Model); ok {
+			if fm.LoadState.Samples != nil {
+				fm.LoadState.Samples.Close() // Removes the temporary file
+			}
+			// Catch anything edited since the last save, e.g. when quitting mid-edit with ctrl+c
+			fm.SyncRequestToEditor()
+			if err := fm.SaveRequests(); err != nil {
+				fmt.Printf("Failed to save workspace: %v\n", err)
+				os.Exit(1)
+			}
+		}
 	},
 }
 
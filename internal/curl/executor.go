@@ -2,14 +2,34 @@ package curl
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"lazycurl/internal/model"
+	"net/url"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// metadataSeparator separates the response body from the --write-out block.
+const metadataSeparator = "_____LAZYCURL_METADATA_____"
+
+// writeOut is the --write-out template; one key=value pair per line after the separator.
+var writeOut = "\n" + metadataSeparator + "\n" + strings.Join([]string{
+	"http_code=%{http_code}",
+	"time_namelookup=%{time_namelookup}",
+	"time_connect=%{time_connect}",
+	"time_appconnect=%{time_appconnect}",
+	"time_starttransfer=%{time_starttransfer}",
+	"time_total=%{time_total}",
+	"size_download=%{size_download}",
+	"remote_ip=%{remote_ip}",
+	"http_version=%{http_version}",
+	"num_redirects=%{num_redirects}",
+}, "\n")
+
 // Executor handles executing curl commands.
 type Executor struct{}
 
@@ -19,9 +39,27 @@ func NewExecutor() *Executor {
 }
 
 // Execute runs a curl command based on the request model.
-func (e *Executor) Execute(req model.Request) model.Response {
-	// Build curl arguments
-	args := []string{"-s", "-w", "%{http_code}\n%{time_total}", "-X", req.Method}
+// curl is invoked exactly once per call so non-idempotent requests are never repeated.
+// Cancelling ctx kills the curl process.
+func (e *Executor) Execute(ctx context.Context, req model.Request) model.Response {
+	// Headers are dumped to a temp file so they don't get mixed into the body
+	headerFile, err := os.CreateTemp("", "lazycurl-headers-*")
+	if err != nil {
+		return model.Response{Error: fmt.Errorf("failed to create header dump file: %v", err)}
+	}
+	headerFile.Close()
+	defer os.Remove(headerFile.Name())
+
+	// Build curl arguments. -g turns off URL globbing so braces and brackets are sent literally.
+	args := []string{"-s", "-S", "-g", "-D", headerFile.Name(), "-w", writeOut, "-X", req.Method}
+
+	// Timeouts are enforced by curl itself so a timeout is reported as curl's error
+	if req.Timeout > 0 {
+		args = append(args, "--max-time", formatSeconds(req.Timeout))
+	}
+	if req.ConnectTimeout > 0 {
+		args = append(args, "--connect-timeout", formatSeconds(req.ConnectTimeout))
+	}
 
 	// Add headers
 	for k, v := range req.Headers {
@@ -29,59 +67,43 @@ func (e *Executor) Execute(req model.Request) model.Response {
 	}
 
 	// Add body if present
-	if req.Body != "" {
-		args = append(args, "-d", req.Body)
+	if ct := req.BodyContentType(); req.HasBody() && ct != "" && !req.HasHeader("Content-Type") {
+		args = append(args, "-H", "Content-Type: "+ct)
 	}
+	args = append(args, bodyArgs(req)...)
 
 	// Add URL
 	args = append(args, req.URL)
 
-	cmd := exec.Command("curl", args...)
+	cmd := exec.CommandContext(ctx, "curl", args...)
+	if req.HasBody() && (req.BodyMode == model.BodyRaw || req.BodyMode == model.BodyJSON) {
+		cmd.Stdin = strings.NewReader(req.Body)
+	}
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	start := time.Now()
-	err := cmd.Run()
+	err = cmd.Run()
 	duration := time.Since(start)
 
-	if err != nil {
+	if ctx.Err() != nil {
 		return model.Response{
-			Error:     fmt.Errorf("curl execution failed: %v\nstderr: %s", err, stderr.String()),
+			Error:     fmt.Errorf("request cancelled: %w", ctx.Err()),
 			TimeTaken: duration,
 		}
 	}
-
-	// Parse output. We used a separator in -w to distinguish body from metadata.
-
-	// Re-defining the command with separator
-	args = []string{"-s", "-w", "\n_____LAZYCURL_METADATA_____\n%{http_code}\n%{time_total}", "-X", req.Method}
-	for k, v := range req.Headers {
-		args = append(args, "-H", fmt.Sprintf("%s: %s", k, v))
-	}
-	if req.Body != "" {
-		args = append(args, "-d", req.Body)
-	}
-	args = append(args, req.URL)
-
-	cmd = exec.Command("curl", args...)
-	stdout.Reset()
-	stderr.Reset()
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err = cmd.Run()
 	if err != nil {
 		return model.Response{
-			Error:     fmt.Errorf("curl error: %v, stderr: %s", err, stderr.String()),
+			Error:     fmt.Errorf("curl execution failed: %v\nstderr: %s", err, stderr.String()),
 			TimeTaken: duration,
 		}
 	}
 
+	// Split from the end so a body that happens to contain the separator is kept intact
 	fullOutput := stdout.String()
-	parts := strings.Split(fullOutput, "_____LAZYCURL_METADATA_____")
-
-	if len(parts) < 2 {
+	idx := strings.LastIndex(fullOutput, "\n"+metadataSeparator+"\n")
+	if idx < 0 {
 		return model.Response{
 			Body:      fullOutput,
 			Error:     fmt.Errorf("failed to parse curl metadata"),
@@ -89,23 +111,68 @@ func (e *Executor) Execute(req model.Request) model.Response {
 		}
 	}
 
-	body := parts[0]
-	meta := strings.TrimSpace(parts[1])
-	metaLines := strings.Split(meta, "\n")
+	headerDump, err := os.ReadFile(headerFile.Name())
+	if err != nil {
+		return model.Response{
+			Body:      fullOutput[:idx],
+			Error:     fmt.Errorf("failed to read response headers: %v", err),
+			TimeTaken: duration,
+		}
+	}
+
+	resp := parseWriteOut(fullOutput[idx+len(metadataSeparator)+2:])
+	resp.Body = fullOutput[:idx]
+	resp.Headers = parseHeaderDump(string(headerDump))
 
-	statusCode := 0
-	if len(metaLines) >= 1 {
-		statusCode, _ = strconv.Atoi(strings.TrimSpace(metaLines[0]))
+	// Prefer curl's own measurement; the wall clock also includes process startup
+	resp.TimeTaken = resp.Timing.Total
+	if resp.TimeTaken == 0 {
+		resp.TimeTaken = duration
 	}
 
-	// curl time_total is in seconds (e.g. 0.123)
-	// We'll use our own wall clock `duration` for simplicity and consistency in MVP
-	// unless we really need the curl internal time.
+	return resp
+}
+
+// bodyArgs returns the curl flags sending the body of req. Text bodies are read from
+// stdin with --data-binary, so they go out byte for byte and never as a file name.
+func bodyArgs(req model.Request) []string {
+	if !req.HasBody() {
+		return nil
+	}
+	var args []string
+	switch req.BodyMode {
+	case model.BodyURLEncoded:
+		for _, f := range req.Form {
+			// curl encodes the value, but sends the name as given
+			args = append(args, "--data-urlencode", url.QueryEscape(f.Name)+"="+f.Value)
+		}
+	case model.BodyMultipart:
+		for _, f := range req.Form {
+			if f.File {
+				args = append(args, "-F", f.Name+"=@"+quoteFormFile(f.Value))
+			} else {
+				// Unlike -F, a value starting with @ or < is sent as text
+				args = append(args, "--form-string", f.Name+"="+f.Value)
+			}
+		}
+	case model.BodyBinary:
+		args = append(args, "--data-binary", "@"+req.BodyFile)
+	default:
+		args = append(args, "--data-binary", "@-")
+	}
+	return args
+}
 
-	return model.Response{
-		StatusCode: statusCode,
-		Body:       body,
-		Headers:    nil, // TODO: Parse headers with -D or -i if needed later
-		TimeTaken:  duration,
+// quoteFormFile quotes a file name for -F if it holds characters curl would read
+// as part of the field's options.
+func quoteFormFile(path string) string {
+	if !strings.ContainsAny(path, `;,"\`) {
+		return path
 	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(path) + `"`
+}
+
+// formatSeconds formats a duration the way curl's timeout flags expect (fractional seconds).
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
 }
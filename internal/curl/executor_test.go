@@ -0,0 +1,70 @@
+package curl
+
+import (
+	"lazycurl/internal/model"
+	"slices"
+	"testing"
+)
+
+func TestBodyArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		req  model.Request
+		want []string
+	}{
+		{"no body", model.Request{}, nil},
+		{"raw from stdin", model.Request{Body: "@/etc/passwd"}, []string{"--data-binary", "@-"}},
+		{"json from stdin", model.Request{BodyMode: model.BodyJSON, Body: `{"a":1}`}, []string{"--data-binary", "@-"}},
+		{"empty form", model.Request{BodyMode: model.BodyURLEncoded}, nil},
+		{
+			"urlencoded",
+			model.Request{BodyMode: model.BodyURLEncoded, Form: []model.FormField{
+				{Name: "q", Value: "a b&c"},
+				{Name: "a=b @c", Value: "@not-a-file"},
+			}},
+			// The name is escaped, so the first = always ends it and @ is never read as a file
+			[]string{"--data-urlencode", "q=a b&c", "--data-urlencode", "a%3Db+%40c=@not-a-file"},
+		},
+		{
+			"multipart",
+			model.Request{BodyMode: model.BodyMultipart, Form: []model.FormField{
+				{Name: "note", Value: `a;type=text/html, "quoted"`},
+				{Name: "handle", Value: "@not-a-file"},
+				{Name: "data", Value: "<not-a-file"},
+				{Name: "upload", Value: "/tmp/report.pdf", File: true},
+				{Name: "odd", Value: `/tmp/a;b,"c"\d`, File: true},
+			}},
+			[]string{
+				"--form-string", `note=a;type=text/html, "quoted"`,
+				"--form-string", "handle=@not-a-file",
+				"--form-string", "data=<not-a-file",
+				"-F", "upload=@/tmp/report.pdf",
+				"-F", `odd=@"/tmp/a;b,\"c\"\\d"`,
+			},
+		},
+		{"binary", model.Request{BodyMode: model.BodyBinary, BodyFile: "/tmp/blob.bin"}, []string{"--data-binary", "@/tmp/blob.bin"}},
+		{"binary without a file", model.Request{BodyMode: model.BodyBinary}, nil},
+	}
+	for _, tt := range tests {
+		if got := bodyArgs(tt.req); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: bodyArgs = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteFormFile(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"/tmp/plain file.txt", "/tmp/plain file.txt"},
+		{"/tmp/a;type=x", `"/tmp/a;type=x"`},
+		{"a,b", `"a,b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\data`, `"C:\\data"`},
+	}
+	for _, tt := range tests {
+		if got := quoteFormFile(tt.in); got != tt.want {
+			t.Errorf("quoteFormFile(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
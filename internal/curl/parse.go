@@ -0,0 +1,124 @@
+package curl
+
+import (
+	"lazycurl/internal/model"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// headerBlock is a single response head as written by curl's --dump-header.
+type headerBlock struct {
+	StatusCode int
+	Headers    http.Header
+}
+
+// parseHeaderDump parses the contents of a --dump-header file.
+// When curl follows redirects or receives interim responses (e.g. "100 Continue")
+// the dump holds several blocks, one per response. The headers of the last final
+// (non-1xx) block are returned since that is the response the body belongs to.
+func parseHeaderDump(dump string) http.Header {
+	blocks := parseHeaderBlocks(dump)
+	if len(blocks) == 0 {
+		return http.Header{}
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].StatusCode >= 200 {
+			return blocks[i].Headers
+		}
+	}
+	return blocks[len(blocks)-1].Headers
+}
+
+func parseHeaderBlocks(dump string) []headerBlock {
+	var blocks []headerBlock
+	var current *headerBlock
+	lastKey := ""
+
+	for _, line := range strings.Split(dump, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if strings.HasPrefix(line, "HTTP/") {
+			// Status line starts a new block, e.g. "HTTP/1.1 200 OK" or "HTTP/2 200"
+			blocks = append(blocks, headerBlock{Headers: http.Header{}})
+			current = &blocks[len(blocks)-1]
+			lastKey = ""
+
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				current.StatusCode, _ = strconv.Atoi(fields[1])
+			}
+			continue
+		}
+
+		if current == nil || line == "" {
+			// Blank line terminates the current block
+			current = nil
+			continue
+		}
+
+		// Obsolete line folding: continuation of the previous header value
+		if (line[0] == ' ' || line[0] == '\t') && lastKey != "" {
+			values := current.Headers[lastKey]
+			values[len(values)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		lastKey = http.CanonicalHeaderKey(strings.TrimSpace(k))
+		current.Headers.Add(lastKey, strings.TrimSpace(v))
+	}
+
+	return blocks
+}
+
+// parseWriteOut parses the key=value block produced by the writeOut template.
+func parseWriteOut(meta string) model.Response {
+	var resp model.Response
+
+	for _, line := range strings.Split(strings.TrimSpace(meta), "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+
+		switch k {
+		case "http_code":
+			resp.StatusCode, _ = strconv.Atoi(v)
+		case "time_namelookup":
+			resp.Timing.NameLookup = parseSeconds(v)
+		case "time_connect":
+			resp.Timing.Connect = parseSeconds(v)
+		case "time_appconnect":
+			resp.Timing.AppConnect = parseSeconds(v)
+		case "time_starttransfer":
+			resp.Timing.StartTransfer = parseSeconds(v)
+		case "time_total":
+			resp.Timing.Total = parseSeconds(v)
+		case "size_download":
+			resp.SizeDownload, _ = strconv.ParseInt(v, 10, 64)
+		case "remote_ip":
+			resp.RemoteIP = v
+		case "http_version":
+			resp.HTTPVersion = v
+		case "num_redirects":
+			resp.NumRedirects, _ = strconv.Atoi(v)
+		}
+	}
+
+	return resp
+}
+
+// parseSeconds converts curl's fractional seconds (e.g. "0.123456") to a duration.
+func parseSeconds(v string) time.Duration {
+	secs, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(secs * float64(time.Second))
+}
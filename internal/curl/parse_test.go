@@ -0,0 +1,72 @@
+package curl
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHeaderDump(t *testing.T) {
+	tests := []struct {
+		name string
+		dump string
+		want http.Header
+	}{
+		{
+			name: "single response",
+			dump: "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\ncontent-length: 2\r\n\r\n",
+			want: http.Header{"Content-Type": {"application/json"}, "Content-Length": {"2"}},
+		},
+		{
+			name: "redirects",
+			dump: "HTTP/1.1 301 Moved Permanently\r\nLocation: /a\r\nSet-Cookie: hop=1\r\n\r\n" +
+				"HTTP/1.1 302 Found\r\nLocation: /b\r\n\r\n" +
+				"HTTP/2 200\r\ncontent-type: text/plain\r\n\r\n",
+			want: http.Header{"Content-Type": {"text/plain"}},
+		},
+		{
+			name: "100 continue",
+			dump: "HTTP/1.1 100 Continue\r\n\r\nHTTP/1.1 201 Created\r\nLocation: /users/1\r\n\r\n",
+			want: http.Header{"Location": {"/users/1"}},
+		},
+		{
+			name: "proxy connect",
+			dump: "HTTP/1.1 200 Connection established\r\n\r\nHTTP/1.1 204 No Content\r\nX-Id: 7\r\n\r\n",
+			want: http.Header{"X-Id": {"7"}},
+		},
+		{
+			name: "repeated headers",
+			dump: "HTTP/1.1 200 OK\r\nSet-Cookie: a=1\r\nset-cookie: b=2\r\nVary: Accept\r\nVary: Origin\r\n\r\n",
+			want: http.Header{"Set-Cookie": {"a=1", "b=2"}, "Vary": {"Accept", "Origin"}},
+		},
+		{
+			name: "folded header and colon in value",
+			dump: "HTTP/1.1 200 OK\nX-Long: first\n\tsecond\nX-Time: 12:30:00\n\n",
+			want: http.Header{"X-Long": {"first second"}, "X-Time": {"12:30:00"}},
+		},
+		{
+			name: "only interim responses",
+			dump: "HTTP/1.1 103 Early Hints\r\nLink: </style.css>\r\n\r\n",
+			want: http.Header{"Link": {"</style.css>"}},
+		},
+		{
+			name: "empty",
+			dump: "",
+			want: http.Header{},
+		},
+	}
+	for _, tt := range tests {
+		if got := parseHeaderDump(tt.dump); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseWriteOut(t *testing.T) {
+	resp := parseWriteOut("\nhttp_code=404\ntime_connect=0.001500\ntime_total=0.250000\nsize_download=512\nremote_ip=127.0.0.1\nnum_redirects=2\nunknown=x\n")
+	if resp.StatusCode != 404 || resp.Timing.Connect != 1500*time.Microsecond || resp.Timing.Total != 250*time.Millisecond ||
+		resp.SizeDownload != 512 || resp.RemoteIP != "127.0.0.1" || resp.NumRedirects != 2 {
+		t.Errorf("got %+v", resp)
+	}
+}
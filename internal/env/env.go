@@ -0,0 +1,170 @@
+package env
+
+import (
+	"lazycurl/internal/model"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Environment is a named set of variables, e.g. "local", "staging" or "prod".
+type Environment struct {
+	Name string            `json:"name"`
+	Vars map[string]string `json:"vars"`
+}
+
+// Set holds the environments of a workspace and which one is active.
+type Set struct {
+	Active       string        `json:"active,omitempty"` // Empty means no environment
+	Environments []Environment `json:"environments"`
+}
+
+// Get returns the environment with the given name.
+func (s *Set) Get(name string) *Environment {
+	for i := range s.Environments {
+		if s.Environments[i].Name == name {
+			return &s.Environments[i]
+		}
+	}
+	return nil
+}
+
+// Upsert returns the environment with the given name, creating it if needed.
+func (s *Set) Upsert(name string) *Environment {
+	if e := s.Get(name); e != nil {
+		return e
+	}
+	s.Environments = append(s.Environments, Environment{Name: name, Vars: make(map[string]string)})
+	return &s.Environments[len(s.Environments)-1]
+}
+
+// Remove deletes the environment with the given name. It reports whether it existed.
+func (s *Set) Remove(name string) bool {
+	for i := range s.Environments {
+		if s.Environments[i].Name == name {
+			s.Environments = append(s.Environments[:i], s.Environments[i+1:]...)
+			if s.Active == name {
+				s.Active = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveVars returns the variables of the active environment (nil if none is active).
+func (s *Set) ActiveVars() map[string]string {
+	if e := s.Get(s.Active); e != nil {
+		return e.Vars
+	}
+	return nil
+}
+
+// Next makes the following environment active, cycling through "none" after the last one.
+func (s *Set) Next() {
+	if len(s.Environments) == 0 {
+		s.Active = ""
+		return
+	}
+	if s.Active == "" {
+		s.Active = s.Environments[0].Name
+		return
+	}
+	for i, e := range s.Environments {
+		if e.Name == s.Active {
+			if i+1 < len(s.Environments) {
+				s.Active = s.Environments[i+1].Name
+			} else {
+				s.Active = ""
+			}
+			return
+		}
+	}
+	s.Active = ""
+}
+
+// Resolve substitutes {{var}} references in the URL, headers and body (form fields and
+// file paths included) of req.
+// Requests are stored unresolved; this is applied at execution time.
+// References to variables missing from vars are left as-is and returned, sorted.
+func Resolve(req model.Request, vars map[string]string) (model.Request, []string) {
+	missing := make(map[string]bool)
+	sub := func(s string) string {
+		return Expand(s, vars, missing)
+	}
+
+	req.URL = sub(req.URL)
+	req.Body = sub(req.Body)
+	req.ContentType = sub(req.ContentType)
+	req.BodyFile = sub(req.BodyFile)
+	if len(req.Form) > 0 {
+		form := make([]model.FormField, len(req.Form))
+		for i, f := range req.Form {
+			form[i] = model.FormField{Name: sub(f.Name), Value: sub(f.Value), File: f.File}
+		}
+		req.Form = form
+	}
+	if len(req.Headers) > 0 {
+		headers := make(map[string]string, len(req.Headers))
+		for k, v := range req.Headers {
+			headers[sub(k)] = sub(v)
+		}
+		req.Headers = headers
+	}
+
+	var names []string
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return req, names
+}
+
+// ResolveExcept is Resolve leaving references to names untouched and unreported,
+// such as the variables a load test's data file fills in per iteration.
+func ResolveExcept(req model.Request, vars map[string]string, names []string) (model.Request, []string) {
+	vars = maps.Clone(vars)
+	for _, name := range names {
+		delete(vars, name)
+	}
+	req, missing := Resolve(req, vars)
+	return req, slices.DeleteFunc(missing, func(name string) bool { return slices.Contains(names, name) })
+}
+
+// Expand substitutes {{var}} references in s. Whitespace inside the braces is ignored.
+// Names not found in vars are left untouched and, if missing is non-nil, recorded in it.
+func Expand(s string, vars map[string]string, missing map[string]bool) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start+2:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start + 2
+		// In "{{ {{a}} }}" or "{{{a}}}" the reference is the innermost one
+		start += strings.LastIndex(s[start:end], "{{")
+
+		name := strings.TrimSpace(s[start+2 : end])
+		sb.WriteString(s[:start])
+		if value, ok := vars[name]; ok && name != "" {
+			sb.WriteString(value)
+		} else {
+			sb.WriteString(s[start : end+2])
+			if missing != nil && name != "" {
+				missing[name] = true
+			}
+		}
+		s = s[end+2:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
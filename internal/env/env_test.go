@@ -0,0 +1,112 @@
+package env
+
+import (
+	"lazycurl/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{"host": "api.test", "id": "42", "empty": ""}
+	tests := []struct {
+		in      string
+		want    string
+		missing []string
+	}{
+		{"no references", "no references", nil},
+		{"https://{{host}}/users/{{id}}", "https://api.test/users/42", nil},
+		{"{{ host }}", "api.test", nil},
+		{"[{{empty}}]", "[]", nil},
+		{"{{unknown}}/{{id}}", "{{unknown}}/42", []string{"unknown"}},
+		{"{{}}", "{{}}", nil},
+		{"{{host", "{{host", nil},
+		{"}}{{id}}}", "}}42}", nil},
+		{"{{{id}}}", "{42}", nil},
+		{"{{ {{id}} }}", "{{ 42 }}", nil},
+		{"{{{{nope}}}}", "{{{{nope}}}}", []string{"nope"}},
+	}
+	for _, tt := range tests {
+		missing := make(map[string]bool)
+		if got := Expand(tt.in, vars, missing); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		var names []string
+		for name := range missing {
+			names = append(names, name)
+		}
+		if !reflect.DeepEqual(names, tt.missing) {
+			t.Errorf("Expand(%q) missing = %v, want %v", tt.in, names, tt.missing)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	req := model.Request{
+		Method:  "POST",
+		URL:     "{{base}}/items/{{id}}",
+		Headers: map[string]string{"Authorization": "Bearer {{token}}", "{{hdr}}": "x"},
+		Body:    `{"id": "{{id}}", "note": "{{note}}"}`,
+	}
+	vars := map[string]string{"base": "http://localhost", "id": "7", "hdr": "X-Trace"}
+
+	got, missing := Resolve(req, vars)
+	if got.URL != "http://localhost/items/7" {
+		t.Errorf("URL = %q", got.URL)
+	}
+	if got.Body != `{"id": "7", "note": "{{note}}"}` {
+		t.Errorf("Body = %q", got.Body)
+	}
+	wantHeaders := map[string]string{"Authorization": "Bearer {{token}}", "X-Trace": "x"}
+	if !reflect.DeepEqual(got.Headers, wantHeaders) {
+		t.Errorf("Headers = %v, want %v", got.Headers, wantHeaders)
+	}
+	if want := []string{"note", "token"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %v, want %v", missing, want)
+	}
+	if req.Headers["{{hdr}}"] != "x" {
+		t.Error("Resolve modified the stored request headers")
+	}
+}
+
+func TestResolveExcept(t *testing.T) {
+	req := model.Request{URL: "{{base}}/users/{{id}}?q={{q}}", Body: "{{id}}"}
+	vars := map[string]string{"base": "http://localhost", "id": "from-env"}
+
+	got, missing := ResolveExcept(req, vars, []string{"id", "q"})
+	if got.URL != "http://localhost/users/{{id}}?q={{q}}" || got.Body != "{{id}}" {
+		t.Errorf("resolved to %q, body %q; excluded names must stay as references", got.URL, got.Body)
+	}
+	if len(missing) != 0 {
+		t.Errorf("missing = %v, want none for excluded names", missing)
+	}
+	if vars["id"] != "from-env" {
+		t.Error("ResolveExcept modified vars")
+	}
+
+	if _, missing := ResolveExcept(req, nil, []string{"id"}); !reflect.DeepEqual(missing, []string{"base", "q"}) {
+		t.Errorf("missing = %v, want [base q]", missing)
+	}
+}
+
+func TestSetNext(t *testing.T) {
+	set := &Set{}
+	set.Upsert("local")
+	set.Upsert("prod")
+
+	var seen []string
+	for range 4 {
+		set.Next()
+		seen = append(seen, set.Active)
+	}
+	if want := []string{"local", "prod", "", "local"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("Next cycled through %q, want %q", seen, want)
+	}
+
+	set.Active = "prod"
+	if !set.Remove("prod") || set.Active != "" {
+		t.Errorf("Remove(active) left Active = %q", set.Active)
+	}
+	if set.Remove("prod") {
+		t.Error("Remove(missing) = true")
+	}
+}
@@ -0,0 +1,218 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"lazycurl/internal/model"
+	"lazycurl/internal/workspace"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// File is the history log inside a workspace, one JSON entry per line.
+const File = "history.jsonl"
+
+// MaxBodyBytes caps how much of a response body is kept per entry.
+const MaxBodyBytes = 64 * 1024
+
+// Once the log grows past MaxFileBytes, Append trims it to the newest MaxEntries
+// entries that fit in half that size.
+const (
+	MaxEntries   = 1000
+	MaxFileBytes = 16 * 1024 * 1024
+)
+
+// Entry is one recorded request execution.
+type Entry struct {
+	ID            string        `json:"id"`
+	Time          time.Time     `json:"time"`
+	Request       model.Request `json:"request"` // As sent, with variables resolved
+	StatusCode    int           `json:"status_code"`
+	Error         string        `json:"error,omitempty"`
+	TimeTaken     time.Duration `json:"time_taken"`
+	Timing        model.Timing  `json:"timing"`
+	Size          int64         `json:"size"`
+	Headers       http.Header   `json:"headers,omitempty"`
+	Body          string        `json:"body"`
+	BodyTruncated bool          `json:"body_truncated,omitempty"`
+}
+
+// NewEntry records the outcome of sending req.
+func NewEntry(req model.Request, resp model.Response) Entry {
+	e := Entry{
+		ID:         model.NewID(),
+		Time:       time.Now(),
+		Request:    req,
+		StatusCode: resp.StatusCode,
+		TimeTaken:  resp.TimeTaken,
+		Timing:     resp.Timing,
+		Size:       resp.SizeDownload,
+		Headers:    resp.Headers,
+		Body:       resp.Body,
+	}
+	if resp.Error != nil {
+		e.Error = resp.Error.Error()
+	}
+	if e.Size == 0 {
+		e.Size = int64(len(resp.Body))
+	}
+	if len(e.Body) > MaxBodyBytes {
+		// Cut before the rune straddling the limit rather than through it
+		n := MaxBodyBytes
+		for n > 0 && !utf8.RuneStart(e.Body[n]) {
+			n--
+		}
+		e.Body = e.Body[:n]
+		e.BodyTruncated = true
+	}
+	return e
+}
+
+// Response rebuilds the recorded response for display.
+func (e Entry) Response() model.Response {
+	resp := model.Response{
+		StatusCode:   e.StatusCode,
+		Body:         e.Body,
+		Headers:      e.Headers,
+		TimeTaken:    e.TimeTaken,
+		Timing:       e.Timing,
+		SizeDownload: e.Size,
+	}
+	if e.BodyTruncated {
+		resp.Body += "\n... (truncated in history)"
+	}
+	if e.Error != "" {
+		resp.Error = fmt.Errorf("%s", e.Error)
+	}
+	return resp
+}
+
+// Matches reports whether the entry matches a filter query.
+// The query is matched case-insensitively against method, name, URL and status code.
+func (e Entry) Matches(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+	haystack := strings.ToLower(strings.Join([]string{
+		e.Request.Method, e.Request.Name, e.Request.URL, strconv.Itoa(e.StatusCode),
+	}, " "))
+	for _, term := range strings.Fields(query) {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// Store is an append-only history log, trimmed to its newest entries as it grows.
+type Store struct {
+	path       string
+	maxEntries int
+	maxBytes   int64
+	mu         sync.Mutex
+}
+
+// NewStore creates a store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path, maxEntries: MaxEntries, maxBytes: MaxFileBytes}
+}
+
+// Append adds an entry to the end of the log, trimming the log if it grew too large.
+func (s *Store) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %v", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %v", err)
+	}
+	// A single write per entry so a crash can at worst leave one partial last line
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history: %v", err)
+	}
+	info, statErr := f.Stat()
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %v", err)
+	}
+	if statErr == nil && info.Size() > s.maxBytes {
+		return s.trim()
+	}
+	return nil
+}
+
+// trim rewrites the log with only its newest entries, at most maxEntries of them
+// in at most half of maxBytes, so trimming stays rare. Called with mu held.
+func (s *Store) trim() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to trim history: %v", err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var kept [][]byte
+	var size int64
+	for i := len(lines) - 1; i >= 0 && len(kept) < s.maxEntries; i-- {
+		line := lines[i]
+		if !bytes.HasSuffix(line, []byte("\n")) || !json.Valid(line) {
+			continue // Partial line left by a crash
+		}
+		if size += int64(len(line)); size > s.maxBytes/2 {
+			break
+		}
+		kept = append(kept, line)
+	}
+
+	var buf bytes.Buffer
+	for i := len(kept) - 1; i >= 0; i-- {
+		buf.Write(kept[i])
+	}
+	return workspace.WriteFileAtomic(s.path, buf.Bytes())
+}
+
+// Load returns up to limit of the most recent entries, newest first (limit <= 0 means all).
+// Lines that don't parse, such as a partial line left by a crash, are skipped.
+func (s *Store) Load(limit int) ([]Entry, error) {
+	s.mu.Lock()
+	data, err := os.ReadFile(s.path)
+	s.mu.Unlock()
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %v", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024) // Request bodies are stored in full
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	// Newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
@@ -0,0 +1,105 @@
+package history
+
+import (
+	"fmt"
+	"lazycurl/internal/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewEntryTruncatesOnRuneBoundary(t *testing.T) {
+	for pad := range 4 {
+		// "€" is 3 bytes; shift it across the limit
+		body := strings.Repeat("a", MaxBodyBytes-pad) + strings.Repeat("€", 10)
+		e := NewEntry(model.Request{}, model.Response{Body: body})
+		if !e.BodyTruncated || len(e.Body) > MaxBodyBytes || len(e.Body) < MaxBodyBytes-2 {
+			t.Errorf("pad %d: kept %d bytes, truncated %v", pad, len(e.Body), e.BodyTruncated)
+		}
+		if !utf8.ValidString(e.Body) {
+			t.Errorf("pad %d: truncated body ends in a partial rune: %q", pad, e.Body[len(e.Body)-4:])
+		}
+		if e.Size != int64(len(body)) {
+			t.Errorf("pad %d: size %d, want the full %d", pad, e.Size, len(body))
+		}
+	}
+
+	e := NewEntry(model.Request{}, model.Response{Body: "short"})
+	if e.Body != "short" || e.BodyTruncated {
+		t.Errorf("short body stored as %q, truncated %v", e.Body, e.BodyTruncated)
+	}
+}
+
+func TestStoreLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), File)
+	s := NewStore(path)
+	if entries, err := s.Load(0); err != nil || entries != nil {
+		t.Fatalf("missing log loaded %v, %v", entries, err)
+	}
+	for i := range 5 {
+		if err := s.Append(NewEntry(model.Request{URL: fmt.Sprint(i)}, model.Response{})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A partial last line, as a crash mid-write would leave
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"id": "x", "req`)
+	f.Close()
+
+	entries, err := s.Load(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := urls(entries); got != "4 3 2" {
+		t.Errorf("Load(3) = %s, want the newest first", got)
+	}
+}
+
+func TestStoreTrims(t *testing.T) {
+	path := filepath.Join(t.TempDir(), File)
+	s := NewStore(path)
+	s.maxEntries, s.maxBytes = 10, 4096
+
+	for i := range 100 {
+		if err := s.Append(NewEntry(model.Request{URL: fmt.Sprint(i)}, model.Response{Body: strings.Repeat("x", 100)})); err != nil {
+			t.Fatal(err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() > s.maxBytes {
+			t.Fatalf("log grew to %d bytes after %d entries", info.Size(), i+1)
+		}
+	}
+
+	entries, err := s.Load(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || len(entries) > 10 {
+		t.Fatalf("kept %s, want at most the newest 10", urls(entries))
+	}
+	for i, e := range entries {
+		if e.Request.URL != fmt.Sprint(99-i) {
+			t.Errorf("kept %s, want the newest in order", urls(entries))
+			break
+		}
+	}
+
+	// A log trimmed to entries this large keeps fewer of them, within half the size
+	s.maxEntries = 1000
+	big := strings.Repeat("y", 1000)
+	for range 10 {
+		s.Append(NewEntry(model.Request{}, model.Response{Body: big}))
+	}
+	if info, _ := os.Stat(path); info.Size() > s.maxBytes {
+		t.Errorf("log of large entries grew to %d bytes", info.Size())
+	}
+}
+
+func urls(entries []Entry) string {
+	var s []string
+	for _, e := range entries {
+		s = append(s, e.Request.URL)
+	}
+	return strings.Join(s, " ")
+}
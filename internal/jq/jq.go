@@ -0,0 +1,93 @@
+package jq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+// Timeout bounds a single evaluation, so a runaway expression (e.g. `repeat(1)`) can't hang the caller.
+const Timeout = 250 * time.Millisecond
+
+// MaxResults caps how many results an expression may produce.
+const MaxResults = 10000
+
+// Apply runs a jq expression against a JSON body and returns the results as indented JSON,
+// one per line, like `jq .` does. A JSONPath-style leading `$` (e.g. `$.items[0]`) is accepted
+// as an alias for `.`. An empty expression returns the body unchanged.
+func Apply(ctx context.Context, body, expr string) (string, error) {
+	expr = normalize(expr)
+	if expr == "" {
+		return body, nil
+	}
+
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return "", fmt.Errorf("invalid filter: %v", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid filter: %v", err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var input any
+	if err := dec.Decode(&input); err != nil {
+		return "", fmt.Errorf("body is not JSON: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	iter := code.RunWithContext(ctx, input)
+	for n := 0; ; n++ {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("filter took longer than %s", Timeout)
+			}
+			return "", err
+		}
+		if n == MaxResults {
+			return "", fmt.Errorf("filter produced more than %d results", MaxResults)
+		}
+
+		raw, err := gojq.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		if out.Len() > 0 {
+			out.WriteByte('\n')
+		}
+		if err := json.Indent(&out, raw, "", "  "); err != nil {
+			out.Write(raw)
+		}
+	}
+	return out.String(), nil
+}
+
+// normalize trims expr and maps a JSONPath root (`$`, `$.a`, `$[0]`) onto jq's `.`.
+// jq's own `$name` variables, such as `$ENV` or `$__loc__`, are left alone.
+func normalize(expr string) string {
+	expr = strings.TrimSpace(expr)
+	rest, ok := strings.CutPrefix(expr, "$")
+	switch {
+	case !ok:
+		return expr
+	case rest == "", strings.HasPrefix(rest, "["):
+		return "." + rest
+	case strings.HasPrefix(rest, "."):
+		return rest
+	}
+	return expr
+}
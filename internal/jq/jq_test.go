@@ -0,0 +1,50 @@
+package jq
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"  .a  ", ".a"},
+		{"$", "."},
+		{"$.a", ".a"},
+		{"$.items[0].id", ".items[0].id"},
+		{"$[0]", ".[0]"},
+		{"$ENV", "$ENV"},
+		{"$ENV|length", "$ENV|length"},
+		{"$__loc__", "$__loc__"},
+		{".a as $x | $x", ".a as $x | $x"},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.in); got != tt.want {
+			t.Errorf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	body := `{"items": [{"id": 1}, {"id": 2}]}`
+	tests := []struct {
+		expr, want string
+	}{
+		{"", body},
+		{"$.items[1].id", "2"},
+		{"$[\"items\"] | length", "2"},
+		{"$ENV | type", `"object"`},
+	}
+	for _, tt := range tests {
+		got, err := Apply(context.Background(), body, tt.expr)
+		if err != nil {
+			t.Errorf("Apply(%q): %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package load
+
+import (
+	"lazycurl/internal/model"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrorClass is a kind of failed request.
+type ErrorClass string
+
+// Error classes, from the network up. ClassOther is a transport failure that isn't recognised.
+const (
+	ClassDNS     ErrorClass = "dns"
+	ClassRefused ErrorClass = "connection_refused"
+	ClassTLS     ErrorClass = "tls"
+	ClassTimeout ErrorClass = "timeout"
+	ClassReset   ErrorClass = "connection_reset"
+	ClassOther   ErrorClass = "other"
+	ClassHTTP4xx ErrorClass = "http_4xx"
+	ClassHTTP5xx ErrorClass = "http_5xx"
+)
+
+// ErrorClasses lists the classes in display order.
+var ErrorClasses = []ErrorClass{ClassDNS, ClassRefused, ClassTLS, ClassTimeout, ClassReset, ClassOther, ClassHTTP4xx, ClassHTTP5xx}
+
+// ErrorClassStats counts the failures of one class.
+type ErrorClassStats struct {
+	Count     int
+	Sample    string        // Message of the first failure
+	FirstSeen time.Duration // When the first failure completed, into the test
+}
+
+// curlExitCode matches curl's error prefix, e.g. "curl: (28) Operation timed out".
+var curlExitCode = regexp.MustCompile(`curl: \((\d+)\)`)
+
+// Exit codes of curl by class, see "EXIT CODES" in curl(1)
+var curlClasses = map[string]ErrorClass{
+	"5": ClassDNS, "6": ClassDNS,
+	"7":  ClassRefused, // Failed to connect, in practice nearly always refused
+	"28": ClassTimeout,
+	"35": ClassTLS, "51": ClassTLS, "53": ClassTLS, "54": ClassTLS, "58": ClassTLS, "59": ClassTLS,
+	"60": ClassTLS, "64": ClassTLS, "66": ClassTLS, "77": ClassTLS, "80": ClassTLS, "82": ClassTLS,
+	"83": ClassTLS, "90": ClassTLS, "91": ClassTLS,
+	"52": ClassReset, "55": ClassReset, "56": ClassReset,
+}
+
+// Classify tells why a request failed, or returns "" if it didn't.
+// Transport errors are recognised from curl's exit code or the message, so both
+// backends classify alike; a response with a 4xx or 5xx status is an HTTP error.
+func Classify(resp model.Response) ErrorClass {
+	if resp.Error == nil {
+		switch {
+		case resp.StatusCode >= 500:
+			return ClassHTTP5xx
+		case resp.StatusCode >= 400:
+			return ClassHTTP4xx
+		}
+		return ""
+	}
+
+	msg := strings.ToLower(resp.Error.Error())
+	if m := curlExitCode.FindStringSubmatch(msg); m != nil {
+		if class, ok := curlClasses[m[1]]; ok {
+			return class
+		}
+	}
+	// Checked in order: a TLS handshake timeout is a timeout, a refused TLS connection is refused
+	switch {
+	case containsAny(msg, "timeout", "timed out", "deadline exceeded"):
+		return ClassTimeout
+	case containsAny(msg, "no such host", "could not resolve", "server misbehaving", "lookup "):
+		return ClassDNS
+	case containsAny(msg, "connection refused"):
+		return ClassRefused
+	case containsAny(msg, "tls:", "x509:", "certificate", "ssl"):
+		return ClassTLS
+	case containsAny(msg, "connection reset", "broken pipe", "empty reply", "eof"):
+		return ClassReset
+	}
+	return ClassOther
+}
+
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorMessage returns the gist of a failure: curl's own message when there is one
+// (the error's first line only says curl failed), otherwise the first line.
+func errorMessage(err error) string {
+	msg := err.Error()
+	if _, stderr, ok := strings.Cut(msg, "\nstderr: "); ok && strings.TrimSpace(stderr) != "" {
+		msg = strings.TrimSpace(stderr)
+	}
+	first, _, _ := strings.Cut(msg, "\n")
+	return first
+}
@@ -0,0 +1,304 @@
+package load
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"lazycurl/internal/model"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sample is one request of a load test.
+type Sample struct {
+	StartMS    float64    `json:"start_ms"` // When it was sent, since the test started
+	Request    string     `json:"request"`  // Title of the request, or name of the scenario step
+	Status     int        `json:"status"`   // Zero when no response arrived
+	LatencyMS  float64    `json:"latency_ms"`
+	Bytes      int64      `json:"bytes"`
+	ErrorClass ErrorClass `json:"error_class,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+func newSample(name string, start time.Duration, resp model.Response) Sample {
+	s := Sample{
+		StartMS:    millis(start),
+		Request:    name,
+		Status:     resp.StatusCode,
+		LatencyMS:  millis(resp.TimeTaken),
+		Bytes:      resp.SizeDownload,
+		ErrorClass: Classify(resp),
+	}
+	if resp.Error != nil {
+		s.Error = resp.Error.Error()
+	}
+	return s
+}
+
+// sampleColumns is the header of the samples CSV, in Sample's field order.
+var sampleColumns = []string{"start_ms", "request", "status", "latency_ms", "bytes", "error_class", "error"}
+
+func (s Sample) record() []string {
+	return []string{
+		formatFloat(s.StartMS), s.Request, strconv.Itoa(s.Status), formatFloat(s.LatencyMS),
+		strconv.FormatInt(s.Bytes, 10), string(s.ErrorClass), s.Error,
+	}
+}
+
+// SampleLog keeps every request of a test for export. Samples go to a temporary
+// file as JSON lines rather than memory, so long tests stay cheap; Close removes it.
+type SampleLog struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	size int64 // Bytes written so far, flushed or not
+	err  error // First write error; later samples are dropped
+}
+
+// NewSampleLog creates an empty log.
+func NewSampleLog() (*SampleLog, error) {
+	f, err := os.CreateTemp("", "lazycurl-samples-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create samples file: %v", err)
+	}
+	return &SampleLog{file: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (l *SampleLog) record(s Sample) {
+	line, _ := json.Marshal(s)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return
+	}
+	n, err := l.w.Write(append(line, '\n'))
+	l.size += int64(n)
+	l.err = err
+}
+
+// contents returns a reader over the samples logged so far. Logging carries on
+// meanwhile; later samples are appended past its end.
+func (l *SampleLog) contents() (io.Reader, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err == nil {
+		l.err = l.w.Flush()
+	}
+	if l.err != nil {
+		return nil, fmt.Errorf("failed to record samples: %v", l.err)
+	}
+	return io.NewSectionReader(l.file, 0, l.size), nil
+}
+
+// WriteJSONL writes the samples as JSON lines, one object each.
+func (l *SampleLog) WriteJSONL(w io.Writer) error {
+	r, err := l.contents()
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// WriteCSV writes the samples as CSV with a header row.
+func (l *SampleLog) WriteCSV(w io.Writer) error {
+	r, err := l.contents()
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(sampleColumns)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var s Sample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return fmt.Errorf("corrupt samples file: %v", err)
+		}
+		cw.Write(s.record())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteFile writes the samples to path: JSON lines for a .jsonl or .ndjson file, CSV otherwise.
+func (l *SampleLog) WriteFile(path string) error {
+	write := l.WriteCSV
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		write = l.WriteJSONL
+	}
+	return writeExport(path, write)
+}
+
+// Close discards the samples.
+func (l *SampleLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.file.Close()
+	return os.Remove(l.file.Name())
+}
+
+// WritePerSecondCSV writes the per-second windows of a report as CSV with a header row.
+func WritePerSecondCSV(w io.Writer, windows []WindowReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"start_seconds", "requests", "rps", "error_rate", "mean_ms", "p50_ms", "p99_ms", "in_flight"})
+	for _, win := range windows {
+		cw.Write([]string{
+			formatFloat(win.StartS), strconv.Itoa(win.Requests), formatFloat(win.RPS), formatFloat(win.ErrorRate),
+			formatFloat(win.MeanMS), formatFloat(win.P50MS), formatFloat(win.P99MS), strconv.Itoa(win.InFlight),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WritePrometheus writes a snapshot of a report in the Prometheus text exposition
+// format, for a Pushgateway or a file scraped by node_exporter's textfile collector.
+// Latencies are summaries in seconds. Families broken down by scenario step label
+// each series by request, the whole test as request="_all", so summing one
+// excluding "_all" never counts a request twice.
+func WritePrometheus(w io.Writer, r Report) error {
+	bw := bufio.NewWriter(w)
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	sample := func(name string, labels []string, value float64) {
+		if len(labels) > 0 {
+			name += "{" + strings.Join(labels, ",") + "}"
+		}
+		fmt.Fprintf(bw, "%s %s\n", name, formatFloat(value))
+	}
+	summary := func(labels []string, l LatencyReport, count int) {
+		for _, q := range []struct {
+			q  string
+			ms float64
+		}{{"0.5", l.P50}, {"0.9", l.P90}, {"0.95", l.P95}, {"0.99", l.P99}, {"0.999", l.P999}} {
+			sample("lazycurl_load_latency_seconds", append(labels[:len(labels):len(labels)], label("quantile", q.q)), seconds(q.ms))
+		}
+		sample("lazycurl_load_latency_seconds_sum", labels, seconds(l.Mean*float64(count)))
+		sample("lazycurl_load_latency_seconds_count", labels, float64(count))
+	}
+
+	all := []string{label("request", "_all")}
+
+	metric("lazycurl_load_requests_total", "counter", "Requests that completed.")
+	sample("lazycurl_load_requests_total", all, float64(r.Requests))
+	for _, st := range r.PerRequest {
+		sample("lazycurl_load_requests_total", []string{label("request", st.Name)}, float64(st.Requests))
+	}
+	metric("lazycurl_load_failures_total", "counter", "Requests that failed, HTTP errors included.")
+	sample("lazycurl_load_failures_total", all, float64(r.Failures))
+	for _, st := range r.PerRequest {
+		sample("lazycurl_load_failures_total", []string{label("request", st.Name)}, float64(st.Failures))
+	}
+	metric("lazycurl_load_cut_off_total", "counter", "Requests aborted in flight when the test ended.")
+	sample("lazycurl_load_cut_off_total", nil, float64(r.CutOff))
+
+	metric("lazycurl_load_responses_total", "counter", "Responses by status code; 0 is no response.")
+	for _, code := range sortedKeys(r.StatusCodes) {
+		sample("lazycurl_load_responses_total", append([]string{label("code", code)}, all...), float64(r.StatusCodes[code]))
+	}
+	for _, st := range r.PerRequest {
+		for _, code := range sortedKeys(st.StatusCodes) {
+			sample("lazycurl_load_responses_total", []string{label("code", code), label("request", st.Name)}, float64(st.StatusCodes[code]))
+		}
+	}
+	metric("lazycurl_load_errors_total", "counter", "Failures by class.")
+	for _, class := range ErrorClasses {
+		if c, ok := r.ErrorClasses[class]; ok {
+			sample("lazycurl_load_errors_total", []string{label("class", string(class))}, float64(c.Count))
+		}
+	}
+
+	metric("lazycurl_load_latency_seconds", "summary", "Request latency.")
+	summary(all, r.LatencyMS, r.Requests)
+	for _, st := range r.PerRequest {
+		summary([]string{label("request", st.Name)}, st.LatencyMS, st.Requests)
+	}
+
+	metric("lazycurl_load_rps", "gauge", "Average requests per second.")
+	sample("lazycurl_load_rps", nil, r.RPS)
+	metric("lazycurl_load_duration_seconds", "gauge", "How long the test ran.")
+	sample("lazycurl_load_duration_seconds", nil, r.DurationS)
+	if r.TargetRPS > 0 {
+		metric("lazycurl_load_target_rps", "gauge", "Target arrival rate.")
+		sample("lazycurl_load_target_rps", nil, r.TargetRPS)
+		metric("lazycurl_load_dropped_total", "counter", "Iterations dropped at the in-flight cap.")
+		sample("lazycurl_load_dropped_total", nil, float64(r.Dropped))
+		metric("lazycurl_load_late_total", "counter", "Iterations started late.")
+		sample("lazycurl_load_late_total", nil, float64(r.Late))
+	}
+	if len(r.Thresholds) > 0 {
+		metric("lazycurl_load_threshold_pass", "gauge", "1 if the threshold passed, 0 if it failed.")
+	}
+	for _, t := range r.Thresholds {
+		pass := 0.0
+		if t.Pass {
+			pass = 1
+		}
+		sample("lazycurl_load_threshold_pass", []string{label("threshold", t.Threshold)}, pass)
+	}
+	return bw.Flush()
+}
+
+// label formats a Prometheus label pair, escaping the value.
+func label(name, value string) string {
+	return name + `="` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WritePerSecondFile writes the per-second windows of r as CSV to path.
+func WritePerSecondFile(path string, r Report) error {
+	return writeExport(path, func(w io.Writer) error { return WritePerSecondCSV(w, r.PerSecond) })
+}
+
+// WritePrometheusFile writes a Prometheus snapshot of r to path.
+func WritePrometheusFile(path string, r Report) error {
+	return writeExport(path, func(w io.Writer) error { return WritePrometheus(w, r) })
+}
+
+// writeExport creates the file at path and fills it with write.
+func writeExport(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to export: %v", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to export %s: %v", filepath.Base(path), err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to export %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// seconds converts milliseconds to seconds, rounded to the nanosecond so no float
+// noise shows in the output.
+func seconds(ms float64) float64 {
+	return math.Round(ms*1e6) / 1e9
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,157 @@
+package load
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"lazycurl/internal/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSampleLog(t *testing.T) *SampleLog {
+	t.Helper()
+	l, err := NewSampleLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	l.record(newSample("List, all", 1500*time.Microsecond, model.Response{StatusCode: 200, TimeTaken: 2 * time.Millisecond, SizeDownload: 512}))
+	l.record(newSample("Get", 3*time.Millisecond, model.Response{Error: errors.New("connection refused")}))
+	return l
+}
+
+func TestSampleLogJSONL(t *testing.T) {
+	l := testSampleLog(t)
+	var buf bytes.Buffer
+	if err := l.WriteJSONL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	var s Sample
+	if err := json.Unmarshal([]byte(lines[0]), &s); err != nil {
+		t.Fatal(err)
+	}
+	want := Sample{StartMS: 1.5, Request: "List, all", Status: 200, LatencyMS: 2, Bytes: 512}
+	if s != want {
+		t.Errorf("sample = %+v, want %+v", s, want)
+	}
+
+	// Samples recorded after an export show up in the next one
+	l.record(newSample("Get", 5*time.Millisecond, model.Response{StatusCode: 204}))
+	buf.Reset()
+	if err := l.WriteJSONL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 3 {
+		t.Errorf("got %d lines after recording another sample, want 3", n)
+	}
+}
+
+func TestSampleLogCSV(t *testing.T) {
+	l := testSampleLog(t)
+	var buf bytes.Buffer
+	if err := l.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		sampleColumns,
+		{"1.5", "List, all", "200", "2", "512", "", ""},
+		{"3", "Get", "0", "0", "0", "connection_refused", "connection refused"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("csv = %q, want %q", records, want)
+	}
+}
+
+func TestSampleLogWriteFile(t *testing.T) {
+	l := testSampleLog(t)
+	dir := t.TempDir()
+	for name, prefix := range map[string]string{"samples.ndjson": "{", "samples.JSONL": "{", "samples.csv": "start_ms,"} {
+		path := filepath.Join(dir, name)
+		if err := l.WriteFile(path); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(path)
+		if !strings.HasPrefix(string(data), prefix) {
+			t.Errorf("%s starts with %.20q, want %q", name, data, prefix)
+		}
+	}
+
+	if err := l.WriteFile(filepath.Join(dir, "missing", "samples.csv")); err == nil {
+		t.Error("exporting into a missing directory succeeded")
+	}
+}
+
+func TestSampleLogCloseRemovesFile(t *testing.T) {
+	l, err := NewSampleLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := l.file.Name()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("samples file %s left behind", name)
+	}
+}
+
+func TestWritePerSecondCSV(t *testing.T) {
+	var buf bytes.Buffer
+	windows := []WindowReport{{StartS: 0, Requests: 10, RPS: 10, ErrorRate: 0.1, MeanMS: 2.5, P50MS: 2, P99MS: 9.75, InFlight: 3}}
+	if err := WritePerSecondCSV(&buf, windows); err != nil {
+		t.Fatal(err)
+	}
+	want := "start_seconds,requests,rps,error_rate,mean_ms,p50_ms,p99_ms,in_flight\n0,10,10,0.1,2.5,2,9.75,3\n"
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWritePrometheusLabelsTotals(t *testing.T) {
+	s := statsOf(model.Response{StatusCode: 200, TimeTaken: 2 * time.Millisecond}, model.Response{StatusCode: 500, TimeTaken: 4 * time.Millisecond})
+	r := NewReport(s)
+	r.PerRequest = []RequestReport{
+		{Name: "List", Requests: 1, StatusCodes: map[string]int{"200": 1}},
+		{Name: `Say "hi"`, Requests: 1, Failures: 1, StatusCodes: map[string]int{"500": 1}},
+	}
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`lazycurl_load_requests_total{request="_all"} 2`,
+		`lazycurl_load_requests_total{request="List"} 1`,
+		`lazycurl_load_failures_total{request="Say \"hi\""} 1`,
+		`lazycurl_load_responses_total{code="500",request="_all"} 1`,
+		`lazycurl_load_latency_seconds{request="_all",quantile="0.5"} 0.002`,
+		`lazycurl_load_latency_seconds_count{request="_all"} 2`,
+		`lazycurl_load_errors_total{class="http_5xx"} 1`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %s in\n%s", want, out)
+		}
+	}
+	// Families broken down by request have no unlabelled series to double count
+	for _, line := range strings.Split(out, "\n") {
+		for _, family := range []string{"requests_total", "failures_total", "responses_total", "latency_seconds"} {
+			if strings.HasPrefix(line, "lazycurl_load_"+family) && !strings.Contains(line, "request=") {
+				t.Errorf("series without a request label: %s", line)
+			}
+		}
+	}
+}
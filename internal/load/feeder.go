@@ -0,0 +1,190 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// FeedStrategy is how the rows of a data file are handed out to iterations.
+type FeedStrategy string
+
+const (
+	FeedSequential FeedStrategy = "sequential" // In file order, shared by all workers
+	FeedRandom     FeedStrategy = "random"     // Any row, each iteration; never runs out
+	FeedUnique     FeedStrategy = "unique"     // Each worker its own rows, so no two use the same one
+)
+
+// FeedEnd is what happens once every row was used.
+type FeedEnd string
+
+const (
+	FeedRecycle FeedEnd = "recycle" // Start over from the first row
+	FeedStop    FeedEnd = "stop"    // End the test
+)
+
+// ParseFeedStrategy parses a strategy name; empty means sequential.
+func ParseFeedStrategy(s string) (FeedStrategy, error) {
+	switch st := FeedStrategy(strings.ToLower(strings.TrimSpace(s))); st {
+	case "":
+		return FeedSequential, nil
+	case FeedSequential, FeedRandom, FeedUnique:
+		return st, nil
+	}
+	return "", fmt.Errorf("invalid data strategy %q (want sequential, random or unique)", s)
+}
+
+// ParseFeedEnd parses what to do once the data runs out; empty means recycle.
+func ParseFeedEnd(s string) (FeedEnd, error) {
+	switch end := FeedEnd(strings.ToLower(strings.TrimSpace(s))); end {
+	case "":
+		return FeedRecycle, nil
+	case FeedRecycle, FeedStop:
+		return end, nil
+	}
+	return "", fmt.Errorf("invalid end of data %q (want recycle or stop)", s)
+}
+
+// DescribeFeed summarises how rows are used, e.g. "unique, then stop".
+func DescribeFeed(strategy FeedStrategy, end FeedEnd) string {
+	if strategy == FeedRandom {
+		return string(strategy) // Never runs out
+	}
+	return fmt.Sprintf("%s, then %s", strategy, end)
+}
+
+// Feeder supplies the {{variables}} of each iteration from the rows of a data file.
+type Feeder struct {
+	Name     string   // Base name of the file
+	Columns  []string // Variable names, as in the file
+	Strategy FeedStrategy
+	End      FeedEnd
+
+	rows   []map[string]string
+	cursor atomic.Int64 // Next row in sequential order
+}
+
+// LoadFeeder reads a data file: CSV with a header row naming the variables, or, for
+// a .jsonl or .ndjson file, one JSON object per line. Non-string JSON values are used
+// as their JSON text.
+func LoadFeeder(path string, strategy FeedStrategy, end FeedEnd) (*Feeder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %v", err)
+	}
+	f := &Feeder{Name: filepath.Base(path), Strategy: strategy, End: end}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		err = f.parseJSONL(data)
+	default:
+		err = f.parseCSV(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", f.Name, err)
+	}
+	if len(f.rows) == 0 {
+		return nil, fmt.Errorf("%s has no rows", f.Name)
+	}
+	return f, nil
+}
+
+func (f *Feeder) parseCSV(data []byte) error {
+	r := csv.NewReader(bytes.NewReader(data))
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read the header: %v", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	f.Columns = header
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[name] = record[i]
+		}
+		f.rows = append(f.rows, row)
+	}
+}
+
+func (f *Feeder) parseJSONL(data []byte) error {
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &obj); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		row := make(map[string]string, len(obj))
+		for name, raw := range obj {
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				row[name] = s
+			} else {
+				row[name] = string(raw)
+			}
+			if !seen[name] {
+				seen[name] = true
+				f.Columns = append(f.Columns, name)
+			}
+		}
+		f.rows = append(f.rows, row)
+	}
+	sort.Strings(f.Columns) // Objects have no column order
+	return scanner.Err()
+}
+
+// Len returns the number of rows.
+func (f *Feeder) Len() int {
+	return len(f.rows)
+}
+
+// next returns the row for the next iteration of worker i out of workers; used counts
+// the iterations the worker has fed so far. It reports false once the rows run out for
+// everyone, or, with the recycle end, to a unique worker that has none.
+func (f *Feeder) next(i, workers int, used *int) (map[string]string, bool) {
+	n := len(f.rows)
+	switch f.Strategy {
+	case FeedRandom:
+		return f.rows[rand.IntN(n)], true
+	case FeedUnique:
+		if f.End == FeedStop {
+			// Each row is used once, so no two workers can share one; handing them out
+			// in order means the data runs out for all at once, idle workers or not
+			break
+		}
+		// Worker i recycles rows i, i+workers, i+2*workers, ...
+		share := (n - i + workers - 1) / workers
+		if share <= 0 {
+			return nil, false
+		}
+		k := *used
+		*used++
+		return f.rows[i+(k%share)*workers], true
+	}
+	k := int(f.cursor.Add(1) - 1)
+	if k >= n && f.End == FeedStop {
+		return nil, false
+	}
+	return f.rows[k%n], true
+}
@@ -0,0 +1,134 @@
+package load
+
+import (
+	"context"
+	"lazycurl/internal/model"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testFeeder(n int, strategy FeedStrategy, end FeedEnd) *Feeder {
+	f := &Feeder{Columns: []string{"id"}, Strategy: strategy, End: end}
+	for i := range n {
+		f.rows = append(f.rows, map[string]string{"id": strconv.Itoa(i)})
+	}
+	return f
+}
+
+// feed takes count rows for worker i, returning their ids, or -1 once out.
+func feed(f *Feeder, i, workers int, used *int, count int) []int {
+	var ids []int
+	for range count {
+		row, ok := f.next(i, workers, used)
+		if !ok {
+			ids = append(ids, -1)
+			continue
+		}
+		id, _ := strconv.Atoi(row["id"])
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestFeederNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     int
+		strategy FeedStrategy
+		end      FeedEnd
+		worker   int
+		workers  int
+		want     []int
+	}{
+		{"sequential recycles", 3, FeedSequential, FeedRecycle, 0, 2, []int{0, 1, 2, 0, 1}},
+		{"sequential stops", 3, FeedSequential, FeedStop, 0, 2, []int{0, 1, 2, -1, -1}},
+		{"unique recycles its own rows", 7, FeedUnique, FeedRecycle, 1, 3, []int{1, 4, 1, 4}},
+		{"unique last worker", 7, FeedUnique, FeedRecycle, 0, 3, []int{0, 3, 6, 0}},
+		{"unique worker without rows", 2, FeedUnique, FeedRecycle, 2, 3, []int{-1, -1}},
+		{"unique stops", 3, FeedUnique, FeedStop, 2, 3, []int{0, 1, 2, -1}},
+	}
+	for _, tt := range tests {
+		used := 0
+		got := feed(testFeeder(tt.rows, tt.strategy, tt.end), tt.worker, tt.workers, &used, len(tt.want))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got rows %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFeederUniqueStopSharesNoRow(t *testing.T) {
+	f := testFeeder(10, FeedUnique, FeedStop)
+	seen := make(map[int]bool)
+	used := make([]int, 3)
+	for k := range 12 {
+		i := k % 3
+		for _, id := range feed(f, i, 3, &used[i], 1) {
+			if id >= 0 && seen[id] {
+				t.Errorf("row %d used twice", id)
+			}
+			seen[id] = true
+		}
+	}
+	if len(seen) != 11 || !seen[-1] { // Every row, then out
+		t.Errorf("rows used: %v", seen)
+	}
+}
+
+func TestFeederRandom(t *testing.T) {
+	f := testFeeder(3, FeedRandom, FeedStop)
+	used := 0
+	for _, id := range feed(f, 0, 1, &used, 50) {
+		if id < 0 || id > 2 {
+			t.Fatalf("got row %d", id)
+		}
+	}
+}
+
+func TestLoadFeeder(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "users.csv")
+	os.WriteFile(csvPath, []byte("id, name\n1,ada\n2,\"grace, h\"\n"), 0o644)
+	jsonlPath := filepath.Join(dir, "users.jsonl")
+	os.WriteFile(jsonlPath, []byte("{\"name\":\"ada\",\"id\":1}\n\n{\"id\":2,\"tags\":[\"x\"]}\n"), 0o644)
+
+	f, err := LoadFeeder(csvPath, FeedSequential, FeedRecycle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(f.Columns, []string{"id", "name"}) || f.Len() != 2 || f.rows[1]["name"] != "grace, h" {
+		t.Errorf("csv: columns %v, rows %v", f.Columns, f.rows)
+	}
+
+	f, err = LoadFeeder(jsonlPath, FeedSequential, FeedRecycle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(f.Columns, []string{"id", "name", "tags"}) || f.Len() != 2 || f.rows[0]["id"] != "1" || f.rows[1]["tags"] != `["x"]` {
+		t.Errorf("jsonl: columns %v, rows %v", f.Columns, f.rows)
+	}
+
+	empty := filepath.Join(dir, "empty.csv")
+	os.WriteFile(empty, []byte("id\n"), 0o644)
+	if _, err := LoadFeeder(empty, FeedSequential, FeedRecycle); err == nil {
+		t.Error("a file without rows loaded")
+	}
+}
+
+func TestUniqueDataEndsStagedTest(t *testing.T) {
+	// Only one of the four workers runs before the last stage; it alone uses up the data
+	r := NewRunner()
+	r.Executor = slowExecutor{delay: time.Millisecond, status: 200}
+	r.Profile, _ = ParseProfile("100ms:1, 3s:1, 100ms:4")
+	r.Feeder = testFeeder(5, FeedUnique, FeedStop)
+	var s *Stats
+	for msg := range r.Run(context.Background(), model.Request{}, 0, 0) {
+		s = msg.Stats
+	}
+	if !s.DataExhausted || s.TotalRequests != 5 || s.ElapsedTime > time.Second {
+		t.Errorf("data exhausted %v after %d requests in %s; want true, 5, well under the profile", s.DataExhausted, s.TotalRequests, s.ElapsedTime)
+	}
+}
@@ -0,0 +1,142 @@
+package load
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// histSubBits sets the histogram's precision: values up to 2^histSubBits µs are
+// counted exactly, larger ones in buckets that are at most 1/1024 of their value wide.
+const histSubBits = 11
+
+const (
+	histSubCount = 1 << histSubBits
+	histHalf     = histSubCount / 2
+)
+
+// Histogram records latencies in HDR-style log-linear buckets, so its memory is
+// bounded by the range of the values rather than their number, while percentiles
+// stay within 0.1%. Min, max, mean and standard deviation are exact.
+type Histogram struct {
+	counts []int64 // Per bucket; grows to the largest bucket recorded
+	total  int64
+	min    time.Duration
+	max    time.Duration
+	sum    float64 // µs
+	sumSq  float64 // µs²
+}
+
+// NewHistogram creates an empty histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{}
+}
+
+// bucketOf returns the bucket of a value in microseconds.
+func bucketOf(us int64) int {
+	if us < histSubCount {
+		return int(max(us, 0))
+	}
+	shift := bits.Len64(uint64(us)) - histSubBits
+	return shift*histHalf + int(us>>shift)
+}
+
+// bucketRange returns the lowest value of bucket i and its width, in microseconds.
+func bucketRange(i int) (int64, int64) {
+	if i < histSubCount {
+		return int64(i), 1
+	}
+	shift := i/histHalf - 1
+	return int64(i-shift*histHalf) << shift, 1 << shift
+}
+
+// Record adds a value.
+func (h *Histogram) Record(d time.Duration) {
+	us := d.Microseconds()
+	b := bucketOf(us)
+	if b >= len(h.counts) {
+		grown := make([]int64, b+1, max(b+1, 2*len(h.counts)))
+		copy(grown, h.counts)
+		h.counts = grown
+	}
+	h.counts[b]++
+	if h.total == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.total++
+	h.sum += float64(us)
+	h.sumSq += float64(us) * float64(us)
+}
+
+// Count returns the number of values recorded.
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+// Min returns the smallest value recorded.
+func (h *Histogram) Min() time.Duration {
+	return h.min
+}
+
+// Max returns the largest value recorded.
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Mean returns the average value.
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / float64(h.total) * float64(time.Microsecond))
+}
+
+// StdDev returns the population standard deviation.
+func (h *Histogram) StdDev() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	mean := h.sum / float64(h.total)
+	variance := max(h.sumSq/float64(h.total)-mean*mean, 0)
+	return time.Duration(math.Sqrt(variance) * float64(time.Microsecond))
+}
+
+// Percentile returns the p-th percentile (0-100) value, using the nearest-rank method.
+// It reports the middle of the value's bucket, clamped to the recorded range.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	// The epsilon keeps float error from pushing an exact rank, like 99.9% of 10000, up by one
+	rank := min(max(int64(math.Ceil(p*float64(h.total)/100-1e-9)), 1), h.total)
+	var seen int64
+	for i, n := range h.counts {
+		seen += n
+		if seen >= rank {
+			low, width := bucketRange(i)
+			d := time.Duration(low*2+width-1) * time.Microsecond / 2
+			return min(max(d, h.min), h.max)
+		}
+	}
+	return h.max
+}
+
+// CountAbove returns how many values exceeded d. Values in the same bucket as d
+// count as not exceeding it.
+func (h *Histogram) CountAbove(d time.Duration) int64 {
+	var n int64
+	for i := bucketOf(d.Microseconds()) + 1; i < len(h.counts); i++ {
+		n += h.counts[i]
+	}
+	return n
+}
+
+// Clone returns an independent copy.
+func (h *Histogram) Clone() *Histogram {
+	c := *h
+	c.counts = append([]int64(nil), h.counts...)
+	return &c
+}
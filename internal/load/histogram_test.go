@@ -0,0 +1,115 @@
+package load
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBucketOf(t *testing.T) {
+	tests := []struct {
+		us   int64
+		want int
+	}{
+		{-5, 0},
+		{0, 0},
+		{1, 1},
+		{histSubCount - 1, histSubCount - 1}, // Exact up to here
+		{histSubCount, histSubCount},         // Then buckets 2µs wide
+		{histSubCount + 1, histSubCount},
+		{histSubCount + 2, histSubCount + 1},
+		{2*histSubCount - 1, histSubCount + histHalf - 1},
+		{2 * histSubCount, histSubCount + histHalf}, // 4µs wide from here
+	}
+	for _, tt := range tests {
+		if got := bucketOf(tt.us); got != tt.want {
+			t.Errorf("bucketOf(%d) = %d, want %d", tt.us, got, tt.want)
+		}
+	}
+}
+
+func TestBucketRange(t *testing.T) {
+	// Buckets tile the values without gaps, each holding what bucketOf maps to it
+	next := int64(0)
+	for i := range 20 * histHalf {
+		low, width := bucketRange(i)
+		if low != next {
+			t.Fatalf("bucket %d starts at %d, want %d", i, low, next)
+		}
+		if bucketOf(low) != i || bucketOf(low+width-1) != i {
+			t.Fatalf("bucket %d [%d, +%d) maps to %d..%d", i, low, width, bucketOf(low), bucketOf(low+width-1))
+		}
+		if low >= histSubCount && float64(width)/float64(low) > 1.0/1024 {
+			t.Fatalf("bucket %d at %d is %d wide, over 1/1024", i, low, width)
+		}
+		next = low + width
+	}
+}
+
+func TestPercentileExact(t *testing.T) {
+	h := NewHistogram()
+	for us := 1000; us >= 1; us-- {
+		h.Record(time.Duration(us) * time.Microsecond)
+	}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 1}, {1, 10}, {50, 500}, {90, 900}, {95, 950}, {99, 990}, {99.9, 999}, {100, 1000},
+	}
+	for _, tt := range tests {
+		if got := h.Percentile(tt.p); got != tt.want*time.Microsecond {
+			t.Errorf("Percentile(%v) = %s, want %s", tt.p, got, tt.want*time.Microsecond)
+		}
+	}
+	if h.Min() != time.Microsecond || h.Max() != 1000*time.Microsecond || h.Mean() != 500500*time.Nanosecond {
+		t.Errorf("min %s, max %s, mean %s", h.Min(), h.Max(), h.Mean())
+	}
+	// Of 1..n: sqrt((n²-1)/12)
+	if got, want := h.StdDev(), time.Duration(math.Sqrt(999999.0/12)*1000); (got - want).Abs() > time.Nanosecond {
+		t.Errorf("StdDev() = %s, want %s", got, want)
+	}
+}
+
+func TestPercentileWithinPrecision(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	h := NewHistogram()
+	values := make([]time.Duration, 10000)
+	for i := range values {
+		// Log-uniform from 1µs to 10s
+		values[i] = time.Duration(math.Exp(rng.Float64()*math.Log(1e7))) * time.Microsecond
+		h.Record(values[i])
+	}
+	slices.Sort(values)
+	for _, p := range []float64{50, 90, 95, 99, 99.9} {
+		want := values[int(math.Ceil(p*float64(len(values))/100))-1]
+		got := h.Percentile(p)
+		if diff := math.Abs(float64(got-want)) / float64(want); diff > 0.001 {
+			t.Errorf("Percentile(%v) = %s, want %s within 0.1%%", p, got, want)
+		}
+	}
+}
+
+func TestCountAboveAndClone(t *testing.T) {
+	h := NewHistogram()
+	for _, ms := range []int{1, 5, 10, 50, 100} {
+		h.Record(time.Duration(ms) * time.Millisecond)
+	}
+	c := h.Clone()
+	h.Record(time.Second)
+	if got := h.CountAbove(10 * time.Millisecond); got != 3 {
+		t.Errorf("CountAbove(10ms) = %d, want 3", got)
+	}
+	if c.Count() != 5 || c.CountAbove(10*time.Millisecond) != 2 || c.Max() != 100*time.Millisecond {
+		t.Errorf("the clone changed with the original")
+	}
+}
+
+func TestEmptyHistogram(t *testing.T) {
+	h := NewHistogram()
+	if h.Percentile(99) != 0 || h.Mean() != 0 || h.StdDev() != 0 || h.CountAbove(0) != 0 {
+		t.Error("an empty histogram reports values")
+	}
+}
@@ -0,0 +1,184 @@
+package load
+
+import (
+	"strconv"
+	"time"
+)
+
+// Report is the machine-readable summary of a finished load test.
+type Report struct {
+	Requests        int            `json:"requests"`         // Completed, including failures
+	TransportErrors int            `json:"transport_errors"` // Failed without an HTTP response
+	ErrorRate       float64        `json:"error_rate"`       // Failures of any class
+	CutOff          int            `json:"cut_off"`          // Aborted in flight at the end; not counted
+	Aborted         bool           `json:"aborted"`
+	DurationS       float64        `json:"duration_seconds"`
+	RPS             float64        `json:"rps"`
+	TargetRPS       float64        `json:"target_rps,omitempty"` // Only in RPS mode
+	Dropped         int            `json:"dropped"`              // RPS mode: iterations skipped at the in-flight cap
+	Late            int            `json:"late"`                 // RPS mode: iterations started behind schedule
+	StatusCodes     map[string]int `json:"status_codes"`         // Status code -> count; "0" is a failed request
+	LatencyMS       LatencyReport  `json:"latency_ms"`
+	ErrorBreakdown  map[string]int `json:"error_breakdown"` // Error message -> count
+	Failures        int            `json:"failures"`        // Of any error class, HTTP 4xx/5xx included
+
+	ErrorClasses map[ErrorClass]ErrorClassReport `json:"error_classes"`
+
+	PerSecond  []WindowReport    `json:"per_second"`            // The last hour at most
+	Stages     []StageReport     `json:"stages,omitempty"`      // Multi-stage tests only
+	PerRequest []RequestReport   `json:"per_request,omitempty"` // Scenarios only
+	Thresholds []ThresholdResult `json:"thresholds,omitempty"`
+	AbortedBy  string            `json:"aborted_by,omitempty"` // Threshold that ended the test early
+
+	DataExhausted bool `json:"data_exhausted,omitempty"` // The data file ran out, which ended the test
+}
+
+// LatencyReport holds latency statistics in milliseconds.
+type LatencyReport struct {
+	Min    float64 `json:"min"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	P50    float64 `json:"p50"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	P99    float64 `json:"p99"`
+	P999   float64 `json:"p99_9"`
+	Max    float64 `json:"max"`
+}
+
+// ErrorClassReport describes the failures of one class.
+type ErrorClassReport struct {
+	Count      int     `json:"count"`
+	Sample     string  `json:"sample"` // Message of the first failure
+	FirstSeenS float64 `json:"first_seen_seconds"`
+}
+
+// WindowReport holds the results of one second of the test.
+type WindowReport struct {
+	StartS    float64 `json:"start_seconds"`
+	Requests  int     `json:"requests"`
+	RPS       float64 `json:"rps"`
+	ErrorRate float64 `json:"error_rate"` // Failures of any class
+	MeanMS    float64 `json:"mean_ms"`
+	P50MS     float64 `json:"p50_ms"`
+	P99MS     float64 `json:"p99_ms"`
+	InFlight  int     `json:"in_flight"`
+}
+
+// StageReport holds the results of one stage of a profile.
+type StageReport struct {
+	Stage     string        `json:"stage"` // e.g. "ramp to 50 workers over 30s"
+	DurationS float64       `json:"duration_seconds"`
+	Target    float64       `json:"target"`
+	Unit      string        `json:"unit"` // What the target counts: workers or rps
+	Requests  int           `json:"requests"`
+	Errors    int           `json:"errors"` // Of any error class
+	RPS       float64       `json:"rps"`
+	LatencyMS LatencyReport `json:"latency_ms"`
+}
+
+// RequestReport holds the results of one request of a scenario.
+type RequestReport struct {
+	Name        string         `json:"name"`
+	Requests    int            `json:"requests"`
+	Failures    int            `json:"failures"` // Of any error class
+	StatusCodes map[string]int `json:"status_codes"`
+	LatencyMS   LatencyReport  `json:"latency_ms"`
+}
+
+// NewReport summarises s.
+func NewReport(s *Stats) Report {
+	r := Report{
+		Requests:        s.TotalRequests,
+		TransportErrors: s.TransportErrors(),
+		CutOff:          s.CutOff,
+		Aborted:         s.Aborted,
+		DurationS:       s.ElapsedTime.Seconds(),
+		RPS:             s.RPS(),
+		TargetRPS:       s.TargetRPS,
+		Dropped:         s.Dropped,
+		Late:            s.Late,
+		ErrorBreakdown:  make(map[string]int, len(s.Errors)),
+		Failures:        s.Failures(),
+		ErrorClasses:    make(map[ErrorClass]ErrorClassReport, len(s.ErrorClasses)),
+		Thresholds:      s.Thresholds,
+		AbortedBy:       s.AbortedBy,
+		DataExhausted:   s.DataExhausted,
+	}
+	if r.Requests > 0 {
+		r.ErrorRate = float64(r.Failures) / float64(r.Requests)
+		r.LatencyMS = latencyReport(s.Latency)
+	}
+	for _, w := range s.Windows() {
+		r.PerSecond = append(r.PerSecond, WindowReport{
+			StartS:    w.Start.Seconds(),
+			Requests:  w.Requests,
+			RPS:       w.RPS(),
+			ErrorRate: w.ErrorRate(),
+			MeanMS:    millis(w.Mean),
+			P50MS:     millis(w.P50),
+			P99MS:     millis(w.P99),
+			InFlight:  w.InFlight,
+		})
+	}
+	for i, st := range s.Stages {
+		r.Stages = append(r.Stages, StageReport{
+			Stage:     s.Profile.Describe(i),
+			DurationS: s.StageElapsed(i).Seconds(),
+			Target:    s.Profile.Stages[i].Target,
+			Unit:      s.Profile.Unit(),
+			Requests:  st.Requests,
+			Errors:    st.Errors,
+			RPS:       s.StageRPS(i),
+			LatencyMS: latencyReport(st.Latency),
+		})
+	}
+	for _, st := range s.Steps {
+		r.PerRequest = append(r.PerRequest, RequestReport{
+			Name:        st.Name,
+			Requests:    st.Requests,
+			Failures:    st.Failures,
+			StatusCodes: statusCodes(st.StatusCodes),
+			LatencyMS:   latencyReport(st.Latency),
+		})
+	}
+	r.StatusCodes = statusCodes(s.StatusCodes)
+	for class, c := range s.ErrorClasses {
+		r.ErrorClasses[class] = ErrorClassReport{Count: c.Count, Sample: c.Sample, FirstSeenS: c.FirstSeen.Seconds()}
+	}
+	for msg, n := range s.Errors {
+		r.ErrorBreakdown[msg] = n
+	}
+	return r
+}
+
+// statusCodes keys counts by status code as text, as JSON requires.
+func statusCodes(counts map[int]int) map[string]int {
+	codes := make(map[string]int, len(counts))
+	for code, n := range counts {
+		codes[strconv.Itoa(code)] = n
+	}
+	return codes
+}
+
+// latencyReport summarises h in milliseconds.
+func latencyReport(h *Histogram) LatencyReport {
+	if h.Count() == 0 {
+		return LatencyReport{}
+	}
+	return LatencyReport{
+		Min:    millis(h.Min()),
+		Mean:   millis(h.Mean()),
+		StdDev: millis(h.StdDev()),
+		P50:    millis(h.Percentile(50)),
+		P90:    millis(h.Percentile(90)),
+		P95:    millis(h.Percentile(95)),
+		P99:    millis(h.Percentile(99)),
+		P999:   millis(h.Percentile(99.9)),
+		Max:    millis(h.Max()),
+	}
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
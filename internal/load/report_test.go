@@ -0,0 +1,19 @@
+package load
+
+import (
+	"errors"
+	"lazycurl/internal/model"
+	"testing"
+)
+
+func TestReportErrorRate(t *testing.T) {
+	resps := append(repeat(model.Response{StatusCode: 200}, 6), model.Response{StatusCode: 500}, model.Response{StatusCode: 404})
+	resps = append(resps, repeat(model.Response{Error: errors.New("connection refused")}, 2)...)
+	r := NewReport(statsOf(resps...))
+	if r.Failures != 4 || r.TransportErrors != 2 {
+		t.Errorf("failures %d, transport errors %d; want 4, 2", r.Failures, r.TransportErrors)
+	}
+	if r.ErrorRate != 0.4 {
+		t.Errorf("error rate %v, want 0.4", r.ErrorRate)
+	}
+}
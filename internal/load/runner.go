@@ -1,22 +1,78 @@
 package load
 
 import (
-	"lazycurl/internal/curl"
+	"context"
+	"errors"
+	"lazycurl/internal/env"
 	"lazycurl/internal/model"
+	"lazycurl/internal/transport"
+	"math"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Runner orchestrates the load test.
 type Runner struct {
 	Stats    *Stats
-	Executor *curl.Executor
+	Executor transport.Executor
+
+	// DrainTimeout is how long in-flight requests may take to finish once the duration is up.
+	DrainTimeout time.Duration
+
+	// MaxRequests stops the test once this many requests were issued; zero means no limit.
+	// A flow of a Scenario counts as one.
+	MaxRequests int
+
+	// Thresholds are evaluated continuously into Stats.Thresholds.
+	Thresholds []Threshold
+	// AbortOnFail ends the test as soon as a threshold can no longer pass.
+	AbortOnFail bool
+
+	// RPS switches to an open model: iterations start at this rate whether or not
+	// earlier ones have finished, with at most concurrency in flight. Zero keeps the
+	// closed loop, where each worker starts its next request when the last one returns.
+	RPS float64
+
+	// Profile, when it has stages, replaces the flat concurrency (or RPS) and duration:
+	// the number of workers, or the arrival rate, follows the stages instead.
+	// In a rate profile concurrency still caps the requests in flight.
+	Profile Profile
+
+	// Feeder, if set, fills the request's remaining {{variables}} per iteration
+	// from a row of a data file.
+	Feeder *Feeder
+
+	// Scenario, when it has steps, is sent instead of the request given to Run,
+	// and the stats break the results down per request.
+	Scenario Scenario
+
+	// Samples, if set, records every completed request for export.
+	Samples *SampleLog
+}
+
+// result is a completed request of step (of the scenario) step, sent start into the test.
+type result struct {
+	resp  model.Response
+	step  int
+	start time.Duration
 }
 
+const (
+	// lateTolerance is how far behind its scheduled time an iteration may start
+	// before it counts as late in RPS mode.
+	lateTolerance = 10 * time.Millisecond
+	// stagePoll is how often a worker parked by a profile checks whether it's needed again.
+	stagePoll = 50 * time.Millisecond
+)
+
 // NewRunner creates a new runner.
+// Each runner gets its own backends so connection pools aren't shared between tests.
 func NewRunner() *Runner {
 	return &Runner{
-		Stats:    NewStats(),
-		Executor: curl.NewExecutor(),
+		Stats:        NewStats(),
+		Executor:     transport.NewMux(transport.Curl),
+		DrainTimeout: 5 * time.Second,
 	}
 }
 
@@ -26,58 +82,229 @@ type StatsMsg struct {
 	Done  bool
 }
 
-// Run executes the load test. It returns a channel that emits updates.
-// We return a tea.Cmd wrapper instead? No, we need it to stream.
-// In Bubbletea, we can return a "Command" that wraps a subscription, or use a channel command.
-// We'll use the "Program.Program" style or "Sub" style.
-// But for simplicity here, this method spawns the work and the caller (Update) will listen to a channel.
-// Actually, standard Bubbletea pattern for streaming is:
-// Update() returns a Cmd that waits for the next value.
-// So we should return a channel, and have a "WaitForStats(chan)" command.
-
-func (r *Runner) Run(req model.Request, concurrency int, duration time.Duration) chan StatsMsg {
+// Run executes the load test in the background and returns a channel of stats updates,
+// closed after the final one (Done). Cancelling ctx aborts the test early; the final
+// message still carries the partial stats.
+func (r *Runner) Run(ctx context.Context, req model.Request, concurrency int, duration time.Duration) chan StatsMsg {
 	ch := make(chan StatsMsg)
+	staged, open := len(r.Profile.Stages) > 0, r.RPS > 0
+	sc := r.Scenario
+	if len(sc.Steps) > 0 {
+		r.Stats.Steps = make([]StepStats, len(sc.Steps))
+		for i, st := range sc.Steps {
+			r.Stats.Steps[i] = StepStats{Name: st.Name, StatusCodes: make(map[int]int), Latency: NewHistogram()}
+		}
+	} else {
+		sc.Steps = []ScenarioStep{{Name: req.Title(), Request: req, Weight: 1}}
+	}
+	if staged {
+		open = r.Profile.Rate
+		duration = r.Profile.Duration()
+		r.Stats.Profile = r.Profile
+		r.Stats.Stages = make([]StageStats, len(r.Profile.Stages))
+		for i := range r.Stats.Stages {
+			r.Stats.Stages[i].Latency = NewHistogram()
+		}
+		if !r.Profile.Rate {
+			concurrency = int(math.Ceil(r.Profile.Max()))
+		}
+	}
 
 	go func() {
-		// Control channels
-		stop := time.After(duration)
-		results := make(chan model.Response, concurrency)
+		// Two levels of shutdown: when the duration is up workers stop issuing new
+		// requests (issueCtx), in-flight requests get DrainTimeout to finish before
+		// they are aborted too (reqCtx). Cancelling ctx aborts everything at once.
+		reqCtx, cancelRequests := context.WithCancel(ctx)
+		defer cancelRequests()
+		// A zero duration runs until MaxRequests were issued
+		issueCtx, stopIssuing := context.WithCancel(reqCtx)
+		if duration > 0 {
+			issueCtx, stopIssuing = context.WithTimeout(reqCtx, duration)
+		}
+		defer stopIssuing()
+
+		r.Stats.TargetRPS = r.RPS
+		results := make(chan result, concurrency)
+		var cutOff, issued, dropped, late, inFlight atomic.Int64
+		var dataExhausted atomic.Bool
+		startTime := time.Now()
+
+		// send sends the request of a step, with the variables of row filled in.
+		send := func(step int, row map[string]string) {
+			req := sc.Steps[step].Request
+			if row != nil {
+				req, _ = env.Resolve(req, row)
+			}
+			inFlight.Add(1)
+			start := time.Since(startTime)
+			resp := r.Executor.Execute(reqCtx, req)
+			inFlight.Add(-1)
+			if errors.Is(resp.Error, context.Canceled) {
+				// Aborted mid-flight; its latency would be meaningless
+				cutOff.Add(1)
+				return
+			}
+			results <- result{resp: resp, step: step, start: start}
+		}
+
+		// execute sends one iteration of worker i, which fed used rows so far. It
+		// reports false once the data ran out for the worker, which should then stop.
+		execute := func(i int, used *int) bool {
+			var row map[string]string
+			if r.Feeder != nil {
+				var ok bool
+				if row, ok = r.Feeder.next(i, concurrency, used); !ok {
+					// Recycled rows only run out for a unique worker without any of its own
+					if r.Feeder.End == FeedStop {
+						dataExhausted.Store(true)
+						stopIssuing()
+					}
+					return false
+				}
+			}
+
+			if !sc.Flow {
+				send(sc.pick(), row)
+				return true
+			}
+			// A flow is cut short once the test stops issuing, like any new request
+			for step, st := range sc.Steps {
+				if step > 0 && issueCtx.Err() != nil {
+					break
+				}
+				send(step, row)
+				if st.Think > 0 {
+					select {
+					case <-issueCtx.Done():
+					case <-time.After(st.Think):
+					}
+				}
+			}
+			return true
+		}
 
 		// Spawn workers
-		activeWorkers := 0
-		for i := 0; i < concurrency; i++ {
-			activeWorkers++
+		var wg sync.WaitGroup
+		if open {
+			// Open model: the scheduler hands out iterations at the target rate to
+			// whichever worker is idle; with none idle the iteration is dropped.
+			jobs := make(chan struct{})
+			for i := 0; i < concurrency; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					used := 0
+					for range jobs {
+						if !execute(i, &used) {
+							return
+						}
+					}
+				}()
+			}
 			go func() {
-				for {
-					// We need a way to stop workers cleanly.
-					// For MVP, simplistic check?
-					resp := r.Executor.Execute(req)
-					results <- resp
-				}
+				defer close(jobs)
+				r.schedule(issueCtx, startTime, jobs, &issued, &dropped, &late)
 			}()
+		} else {
+			for i := 0; i < concurrency; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					used := 0
+					for issueCtx.Err() == nil {
+						if staged && !r.workerNeeded(i, time.Since(startTime)) {
+							select {
+							case <-issueCtx.Done():
+							case <-time.After(stagePoll):
+							}
+							continue
+						}
+						if r.MaxRequests > 0 && issued.Add(1) > int64(r.MaxRequests) {
+							return
+						}
+						if !execute(i, &used) {
+							return
+						}
+					}
+				}()
+			}
 		}
 
+		// Closing results once every worker is gone is what ends the collector,
+		// so no worker is ever left blocked on a send.
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
 		// Collect results
 		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
 
-		startTime := time.Now()
+		issueDone := issueCtx.Done()
+		var drainDeadline <-chan time.Time
 
 	loop:
 		for {
 			select {
-			case <-stop:
-				break loop
-			case resp := <-results:
-				r.updateStats(resp)
-			case <-ticker.C:
+			case res, ok := <-results:
+				if !ok {
+					break loop
+				}
+				resp := res.resp
+				r.updateStats(resp, time.Since(startTime))
+				if len(r.Stats.Steps) > 0 {
+					r.Stats.recordStep(res.step, resp)
+				}
+				if r.Samples != nil {
+					r.Samples.record(newSample(sc.Steps[res.step].Name, res.start, resp))
+				}
+				if staged {
+					stage, _ := r.Profile.At(res.start)
+					r.Stats.recordStage(stage, resp.TimeTaken, Classify(resp) != "")
+				}
+			case <-issueDone:
+				issueDone = nil // Only fires once
 				r.Stats.ElapsedTime = time.Since(startTime)
-				ch <- StatsMsg{Stats: r.Stats, Done: false}
+				drainDeadline = time.After(r.DrainTimeout)
+			case <-drainDeadline:
+				cancelRequests()
+			case <-ticker.C:
+				if issueDone != nil {
+					r.Stats.ElapsedTime = time.Since(startTime)
+				}
+				r.Stats.CutOff = int(cutOff.Load())
+				r.Stats.Dropped, r.Stats.Late = int(dropped.Load()), int(late.Load())
+				r.Stats.InFlight = int(inFlight.Load())
+				r.Stats.DataExhausted = dataExhausted.Load()
+				if staged && issueDone != nil {
+					r.Stats.Stage, r.Stats.StageTarget = r.Profile.At(time.Since(startTime))
+				}
+				if breached := r.evaluateThresholds(); breached != "" && r.AbortOnFail && r.Stats.AbortedBy == "" {
+					r.Stats.AbortedBy = breached
+					cancelRequests()
+				}
+				if elapsed := time.Since(startTime); r.Stats.windowDue(elapsed) {
+					r.Stats.closeWindow(elapsed, int(inFlight.Load()), false)
+				}
+				// The UI reads its own copy while the runner carries on updating
+				ch <- StatsMsg{Stats: r.Stats.Snapshot(), Done: false}
 			}
 		}
 
 		// Final stats
-		r.Stats.ElapsedTime = time.Since(startTime)
+		// Without a drain phase (request limit reached, or aborted) the test ran until now
+		if issueDone != nil || ctx.Err() != nil {
+			r.Stats.ElapsedTime = time.Since(startTime)
+		}
+		r.Stats.CutOff = int(cutOff.Load())
+		r.Stats.Dropped, r.Stats.Late = int(dropped.Load()), int(late.Load())
+		r.Stats.DataExhausted = dataExhausted.Load()
+		// As of the last tick, since everything has finished by now
+		r.Stats.closeWindow(time.Since(startTime), r.Stats.InFlight, true)
+		r.Stats.InFlight = 0
+		r.Stats.Aborted = ctx.Err() != nil || r.Stats.AbortedBy != ""
+		r.evaluateThresholds()
 		ch <- StatsMsg{Stats: r.Stats, Done: true}
 		close(ch)
 	}()
@@ -85,24 +312,91 @@ func (r *Runner) Run(req model.Request, concurrency int, duration time.Duration)
 	return ch
 }
 
-func (r *Runner) updateStats(resp model.Response) {
-	r.Stats.TotalRequests++
-	r.Stats.StatusCodes[resp.StatusCode]++
+// schedule starts iterations at r.RPS, or the rate of a rate profile, until ctx is
+// done or MaxRequests were started. Iteration n is due at start + n/RPS; after a stall
+// the overdue ones are sent at once (and counted late) so the average rate still
+// matches the target.
+func (r *Runner) schedule(ctx context.Context, start time.Time, jobs chan<- struct{}, issued, dropped, late *atomic.Int64) {
+	dueAt := func(n int) (time.Duration, bool) {
+		return time.Duration(float64(n) / r.RPS * float64(time.Second)), true
+	}
+	if len(r.Profile.Stages) > 0 {
+		// Nothing is due at the start of a ramp from zero, so count from one
+		dueAt = func(n int) (time.Duration, bool) { return r.Profile.timeOf(float64(n + 1)) }
+	}
 
-	ms := float64(resp.TimeTaken.Microseconds()) / 1000.0
-	r.Stats.LatencyPoints = append(r.Stats.LatencyPoints, ms)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
-	// Incremental avg calculation approximation or full re-calc?
-	// Full recalc is expensive.
-	// Running average: avg = avg + (val - avg) / count
-	currentMs := float64(r.Stats.AvgLatency.Microseconds()) / 1000.0
-	newAvgMs := currentMs + (ms-currentMs)/float64(r.Stats.TotalRequests)
-	r.Stats.AvgLatency = time.Duration(newAvgMs * float64(time.Millisecond))
+	for n := 0; ; n++ {
+		if r.MaxRequests > 0 && issued.Load() >= int64(r.MaxRequests) {
+			return
+		}
+		offset, ok := dueAt(n)
+		if !ok {
+			return // The profile is over
+		}
+		due := start.Add(offset)
+		if wait := time.Until(due); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case jobs <- struct{}{}:
+			issued.Add(1)
+			if time.Since(due) > lateTolerance {
+				late.Add(1)
+			}
+		default:
+			dropped.Add(1) // Every worker is busy: the in-flight cap is reached
+		}
+	}
+}
+
+// workerNeeded reports whether worker i (counting from zero) should be running
+// elapsed into a staged test: the profile asks for more than i workers.
+func (r *Runner) workerNeeded(i int, elapsed time.Duration) bool {
+	_, target := r.Profile.At(elapsed)
+	return float64(i) < target
+}
 
-	if resp.TimeTaken < r.Stats.MinLatency {
-		r.Stats.MinLatency = resp.TimeTaken
+// evaluateThresholds updates Stats.Thresholds and returns the first threshold
+// that can no longer pass, if any.
+func (r *Runner) evaluateThresholds() string {
+	if len(r.Thresholds) == 0 {
+		return ""
 	}
-	if resp.TimeTaken > r.Stats.MaxLatency {
-		r.Stats.MaxLatency = resp.TimeTaken
+	breached := ""
+	results := make([]ThresholdResult, len(r.Thresholds))
+	for i, t := range r.Thresholds {
+		results[i] = t.Evaluate(r.Stats, r.MaxRequests)
+		if results[i].Final && breached == "" {
+			breached = t.Raw
+		}
 	}
+	r.Stats.Thresholds = results
+	return breached
+}
+
+func (r *Runner) updateStats(resp model.Response, elapsed time.Duration) {
+	r.Stats.TotalRequests++
+	r.Stats.StatusCodes[resp.StatusCode]++
+	if class := Classify(resp); class != "" {
+		r.Stats.recordFailure(resp, class, elapsed)
+	}
+
+	r.Stats.Latency.Record(resp.TimeTaken)
+	r.Stats.windowReqs++
+	r.Stats.windowHist.Record(resp.TimeTaken)
+
+	r.Stats.AvgLatency = r.Stats.Latency.Mean()
+	r.Stats.MinLatency = r.Stats.Latency.Min()
+	r.Stats.MaxLatency = r.Stats.Latency.Max()
 }
@@ -0,0 +1,99 @@
+package load
+
+import (
+	"fmt"
+	"lazycurl/internal/model"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScenarioStep is one request of a scenario.
+type ScenarioStep struct {
+	Name    string        `json:"name"` // Shown in the per-request breakdown
+	Request model.Request `json:"request"`
+	Weight  float64       `json:"weight,omitempty"` // Mix: relative share of the iterations
+	Think   time.Duration `json:"think,omitempty"`  // Flow: pause after the request
+}
+
+// Scenario replaces the single request of a test. In a mix such as
+// "List users=70, Get user=20, Create user=10" each iteration sends one request
+// picked by weight (1 when left out). In a flow such as "Login -> List users=2s -> Get user"
+// each iteration is a virtual user sending them all in order, pausing for the think
+// time given after a step.
+type Scenario struct {
+	Steps []ScenarioStep
+	Flow  bool
+}
+
+// ParseScenario parses a mix or a flow of saved requests, looked up by name or ID.
+func ParseScenario(s string, lookup func(nameOrID string) (model.Request, error)) (Scenario, error) {
+	var sc Scenario
+	sep := ","
+	if strings.Contains(s, "->") {
+		sc.Flow, sep = true, "->"
+	}
+	for _, part := range strings.Split(s, sep) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := cutLast(part, "=")
+		step := ScenarioStep{Weight: 1}
+		if value != "" {
+			var err error
+			if sc.Flow {
+				step.Think, err = time.ParseDuration(value)
+				if err != nil || step.Think < 0 {
+					return Scenario{}, fmt.Errorf("invalid think time in %q (want e.g. Login=2s)", part)
+				}
+			} else {
+				step.Weight, err = strconv.ParseFloat(value, 64)
+				if err != nil || !(step.Weight > 0) || math.IsInf(step.Weight, 1) { // NaN fails > 0
+					return Scenario{}, fmt.Errorf("invalid weight in %q (want e.g. List users=70)", part)
+				}
+			}
+		}
+		req, err := lookup(name)
+		if err != nil {
+			return Scenario{}, err
+		}
+		step.Name, step.Request = req.Title(), req
+		sc.Steps = append(sc.Steps, step)
+	}
+	return sc, nil
+}
+
+// cutLast slices s around the last instance of sep, trimming both halves.
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return strings.TrimSpace(s), "", false
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+len(sep):]), true
+}
+
+// Describe summarises the scenario, e.g. "mix of 3 requests".
+func (sc Scenario) Describe() string {
+	if sc.Flow {
+		return fmt.Sprintf("flow of %d requests", len(sc.Steps))
+	}
+	return fmt.Sprintf("mix of %d requests", len(sc.Steps))
+}
+
+// pick returns the step of a mix to send next, at random by weight.
+func (sc Scenario) pick() int {
+	total := 0.0
+	for _, st := range sc.Steps {
+		total += st.Weight
+	}
+	x := rand.Float64() * total
+	for i, st := range sc.Steps {
+		if x -= st.Weight; x < 0 {
+			return i
+		}
+	}
+	return len(sc.Steps) - 1
+}
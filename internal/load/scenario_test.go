@@ -0,0 +1,117 @@
+package load
+
+import (
+	"fmt"
+	"lazycurl/internal/model"
+	"math"
+	"testing"
+	"time"
+)
+
+// lookupNames resolves a name to a saved request with that name, failing for "missing".
+func lookupNames(nameOrID string) (model.Request, error) {
+	if nameOrID == "missing" {
+		return model.Request{}, fmt.Errorf("no saved request %q", nameOrID)
+	}
+	return model.Request{Name: nameOrID, Method: "GET", URL: "http://localhost/" + nameOrID}, nil
+}
+
+func TestParseScenarioMix(t *testing.T) {
+	sc, err := ParseScenario("List users=70, Get user=20.5, Create user", lookupNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Flow {
+		t.Error("a mix parsed as a flow")
+	}
+	want := []struct {
+		name   string
+		weight float64
+	}{{"List users", 70}, {"Get user", 20.5}, {"Create user", 1}}
+	if len(sc.Steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(sc.Steps), len(want))
+	}
+	for i, w := range want {
+		st := sc.Steps[i]
+		if st.Name != w.name || st.Weight != w.weight || st.Think != 0 || st.Request.URL != "http://localhost/"+w.name {
+			t.Errorf("step %d = %q weight %v think %s, want %q weight %v", i, st.Name, st.Weight, st.Think, w.name, w.weight)
+		}
+	}
+	if got := sc.Describe(); got != "mix of 3 requests" {
+		t.Errorf("Describe() = %q", got)
+	}
+}
+
+func TestParseScenarioFlow(t *testing.T) {
+	sc, err := ParseScenario("Login -> List users=2s -> Get user = 150ms ->", lookupNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sc.Flow {
+		t.Error("a flow parsed as a mix")
+	}
+	want := []struct {
+		name  string
+		think time.Duration
+	}{{"Login", 0}, {"List users", 2 * time.Second}, {"Get user", 150 * time.Millisecond}}
+	if len(sc.Steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(sc.Steps), len(want))
+	}
+	for i, w := range want {
+		if st := sc.Steps[i]; st.Name != w.name || st.Think != w.think {
+			t.Errorf("step %d = %q think %s, want %q think %s", i, st.Name, st.Think, w.name, w.think)
+		}
+	}
+	if got := sc.Describe(); got != "flow of 3 requests" {
+		t.Errorf("Describe() = %q", got)
+	}
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+	for _, in := range []string{
+		"A=0, B",      // Zero weight: the mix could pick nothing
+		"A=-1, B=2",   // Negative weight
+		"A=x, B",      // Not a number
+		"A=NaN, B",    // Not a share
+		"A=Inf, B",    // Would take every iteration
+		"A -> B=fast", // Not a duration
+		"A -> B=-1s",  // Negative think time
+		"A, missing",  // Unknown request
+	} {
+		if _, err := ParseScenario(in, lookupNames); err == nil {
+			t.Errorf("ParseScenario(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestParseScenarioNameWithEquals(t *testing.T) {
+	// Only the last = separates the weight
+	sc, err := ParseScenario("q=a=3, b", lookupNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Steps[0].Name != "q=a" || sc.Steps[0].Weight != 3 {
+		t.Errorf("step 0 = %q weight %v, want \"q=a\" weight 3", sc.Steps[0].Name, sc.Steps[0].Weight)
+	}
+}
+
+func TestScenarioPick(t *testing.T) {
+	sc := Scenario{Steps: []ScenarioStep{{Weight: 70}, {Weight: 20}, {Weight: 10}}}
+	const n = 20000
+	counts := make([]int, len(sc.Steps))
+	for range n {
+		counts[sc.pick()]++
+	}
+	for i, want := range []float64{0.7, 0.2, 0.1} {
+		if got := float64(counts[i]) / n; math.Abs(got-want) > 0.03 {
+			t.Errorf("step %d picked %.3f of the time, want about %.2f", i, got, want)
+		}
+	}
+
+	single := Scenario{Steps: []ScenarioStep{{Weight: 0.001}}}
+	for range 100 {
+		if i := single.pick(); i != 0 {
+			t.Fatalf("single step mix picked %d", i)
+		}
+	}
+}
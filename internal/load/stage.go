@@ -0,0 +1,145 @@
+package load
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stage is one step of a load profile: over Duration the target moves linearly from
+// the previous stage's target (zero before the first) to Target. Repeating the
+// previous target holds it; a zero Duration jumps straight to Target.
+type Stage struct {
+	Duration time.Duration
+	Target   float64 // Workers, or requests per second in a rate profile
+}
+
+// Profile is a multi-stage load shape such as "30s:50, 2m:50, 10s:200, 30s:0"
+// (ramp to 50 workers, hold, spike to 200, ramp down). Targets written with an
+// "rps" suffix ("1m:100rps") make it a rate profile, which drives the arrival
+// rate instead of the number of workers.
+type Profile struct {
+	Stages []Stage
+	Rate   bool
+}
+
+// ParseProfile parses a comma-separated list of DURATION:TARGET stages.
+func ParseProfile(s string) (Profile, error) {
+	var p Profile
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		dur, target, ok := strings.Cut(part, ":")
+		if !ok {
+			return Profile{}, fmt.Errorf("invalid stage %q (want e.g. 30s:50 or 1m:100rps)", part)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(dur))
+		if err != nil || d < 0 {
+			return Profile{}, fmt.Errorf("invalid duration in stage %q", part)
+		}
+		target, rate := strings.CutSuffix(strings.ToLower(strings.TrimSpace(target)), "rps")
+		t, err := strconv.ParseFloat(strings.TrimSpace(target), 64)
+		if err != nil || t < 0 {
+			return Profile{}, fmt.Errorf("invalid target in stage %q", part)
+		}
+		if len(p.Stages) > 0 && rate != p.Rate {
+			return Profile{}, fmt.Errorf("stage %q: targets must be all workers or all rps", part)
+		}
+		p.Rate = rate
+		p.Stages = append(p.Stages, Stage{Duration: d, Target: t})
+	}
+	if len(p.Stages) > 0 && (p.Duration() == 0 || p.Max() == 0) {
+		return Profile{}, fmt.Errorf("load profile %q never sends anything", s)
+	}
+	return p, nil
+}
+
+// Duration returns the total length of the profile.
+func (p Profile) Duration() time.Duration {
+	var d time.Duration
+	for _, st := range p.Stages {
+		d += st.Duration
+	}
+	return d
+}
+
+// Max returns the highest target of the profile.
+func (p Profile) Max() float64 {
+	m := 0.0
+	for _, st := range p.Stages {
+		m = max(m, st.Target)
+	}
+	return m
+}
+
+// Unit names what the targets count.
+func (p Profile) Unit() string {
+	if p.Rate {
+		return "rps"
+	}
+	return "workers"
+}
+
+// At returns the stage running elapsed into the test and the target at that moment.
+// Past the end it reports the last stage.
+func (p Profile) At(elapsed time.Duration) (int, float64) {
+	var offset time.Duration
+	from := 0.0
+	for i, st := range p.Stages {
+		if elapsed < offset+st.Duration {
+			frac := float64(elapsed-offset) / float64(st.Duration)
+			return i, from + (st.Target-from)*frac
+		}
+		offset += st.Duration
+		from = st.Target
+	}
+	return len(p.Stages) - 1, from
+}
+
+// Describe summarises stage i, e.g. "ramp to 50 workers over 30s".
+func (p Profile) Describe(i int) string {
+	st := p.Stages[i]
+	from := 0.0
+	if i > 0 {
+		from = p.Stages[i-1].Target
+	}
+	target := fmt.Sprintf("%g %s", st.Target, p.Unit())
+	switch {
+	case st.Duration == 0:
+		return "jump to " + target
+	case st.Target == from:
+		return fmt.Sprintf("hold %s for %s", target, st.Duration)
+	}
+	return fmt.Sprintf("ramp to %s over %s", target, st.Duration)
+}
+
+// timeOf returns when the n-th iteration of a rate profile is due: the moment the
+// integral of the rate reaches n. It reports false if the profile ends first.
+func (p Profile) timeOf(n float64) (time.Duration, bool) {
+	var offset time.Duration
+	from, done := 0.0, 0.0
+	for _, st := range p.Stages {
+		secs := st.Duration.Seconds()
+		area := (from + st.Target) / 2 * secs
+		if area > 0 && done+area >= n {
+			// Iterations so far in the stage are from*t + a*t²; solve for t
+			rem := n - done
+			a := (st.Target - from) / (2 * secs)
+			var t float64
+			if math.Abs(a) < 1e-9 {
+				t = rem / from
+			} else {
+				t = (-from + math.Sqrt(max(from*from+4*a*rem, 0))) / (2 * a)
+			}
+			return offset + time.Duration(t*float64(time.Second)), true
+		}
+		done += area
+		offset += st.Duration
+		from = st.Target
+	}
+	return 0, false
+}
@@ -0,0 +1,105 @@
+package load
+
+import (
+	"context"
+	"lazycurl/internal/model"
+	"testing"
+	"time"
+)
+
+func TestParseProfile(t *testing.T) {
+	p, err := ParseProfile("30s:50, 1m:50, 10s:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Rate || len(p.Stages) != 3 || p.Duration() != 100*time.Second || p.Max() != 50 {
+		t.Errorf("got %+v", p)
+	}
+	if p, err := ParseProfile("10s:100rps, 5s:0RPS"); err != nil || !p.Rate {
+		t.Errorf("rate profile: %+v, %v", p, err)
+	}
+	for _, in := range []string{"30s", "x:5", "-1s:5", "1s:-5", "1s:5, 1s:5rps", "0s:5", "1s:0"} {
+		if _, err := ParseProfile(in); err == nil {
+			t.Errorf("ParseProfile(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestProfileAt(t *testing.T) {
+	p, _ := ParseProfile("10s:100, 10s:100, 0s:20, 10s:0")
+	tests := []struct {
+		elapsed time.Duration
+		stage   int
+		target  float64
+	}{
+		{0, 0, 0},
+		{5 * time.Second, 0, 50},
+		{10 * time.Second, 1, 100},
+		{20 * time.Second, 3, 20}, // The jump takes no time
+		{25 * time.Second, 3, 10},
+		{time.Minute, 3, 0}, // Past the end
+	}
+	for _, tt := range tests {
+		stage, target := p.At(tt.elapsed)
+		if stage != tt.stage || target != tt.target {
+			t.Errorf("At(%s) = %d, %v; want %d, %v", tt.elapsed, stage, target, tt.stage, tt.target)
+		}
+	}
+}
+
+func TestProfileTimeOf(t *testing.T) {
+	// 0 -> 10 rps over 2s sends 10, holding 10 rps for 1s another 10, 10 -> 0 over 2s 10 more
+	p, _ := ParseProfile("2s:10rps, 1s:10rps, 2s:0rps")
+	tests := []struct {
+		n    float64
+		want time.Duration
+		ok   bool
+	}{
+		{2.5, time.Second, true},    // 2.5 rps * 1s / 2 after the first second
+		{10, 2 * time.Second, true}, // End of the ramp
+		{15, 2500 * time.Millisecond, true},
+		{20, 3 * time.Second, true},
+		{27.5, 4 * time.Second, true}, // 7.5 of the 10 in the ramp down
+		{30, 5 * time.Second, true},
+		{31, 0, false}, // The profile ends first
+	}
+	for _, tt := range tests {
+		got, ok := p.timeOf(tt.n)
+		if ok != tt.ok || (got-tt.want).Abs() > time.Microsecond {
+			t.Errorf("timeOf(%v) = %s, %v; want %s, %v", tt.n, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// slowExecutor answers every request with status after delay.
+type slowExecutor struct {
+	delay  time.Duration
+	status int
+}
+
+func (e slowExecutor) Execute(ctx context.Context, req model.Request) model.Response {
+	select {
+	case <-ctx.Done():
+		return model.Response{Error: ctx.Err()}
+	case <-time.After(e.delay):
+	}
+	return model.Response{StatusCode: e.status, TimeTaken: e.delay}
+}
+
+func TestStagesCountRequestsWhenStarted(t *testing.T) {
+	r := NewRunner()
+	r.Executor = slowExecutor{delay: 400 * time.Millisecond, status: 500}
+	r.Profile, _ = ParseProfile("500ms:2, 500ms:0")
+	var s *Stats
+	for msg := range r.Run(context.Background(), model.Request{}, 0, 0) {
+		s = msg.Stats
+	}
+	// Most requests start while ramping up but complete while ramping down
+	first, second := s.Stages[0], s.Stages[1]
+	if first.Requests+second.Requests != s.TotalRequests || first.Requests <= second.Requests {
+		t.Errorf("stage requests %d and %d of %d; want most in the first", first.Requests, second.Requests, s.TotalRequests)
+	}
+	if first.Errors != first.Requests || second.Errors != second.Requests {
+		t.Errorf("stage errors %d and %d; want every HTTP 500 counted", first.Errors, second.Errors)
+	}
+}
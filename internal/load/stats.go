@@ -2,30 +2,116 @@ package load
 
 import (
 	"fmt"
-	"sort"
+	"lazycurl/internal/model"
+	"maps"
+	"math"
+	"slices"
 	"time"
 )
 
+const (
+	// maxErrorKinds caps how many distinct error messages are counted; the rest go under "other".
+	maxErrorKinds = 20
+	// maxWindows is how many per-second windows are kept: an hour's worth.
+	maxWindows = 3600
+	// minFinalWindow is the shortest trailing window kept; a shorter one would skew its rate.
+	minFinalWindow = 200 * time.Millisecond
+)
+
 // Stats holds the aggregated metrics of a load test.
 type Stats struct {
 	TotalRequests int
 	ElapsedTime   time.Duration
 	StatusCodes   map[int]int
-	LatencyPoints []float64 // In milliseconds, for plotting
+	Latency       *Histogram // Every completed request
 	AvgLatency    time.Duration
 	MinLatency    time.Duration
 	MaxLatency    time.Duration
+	CutOff        int                            // Requests aborted in flight when the test ended; not counted above
+	Aborted       bool                           // Stopped by the user before the duration was up
+	Errors        map[string]int                 // Failed requests (status 0) by error message
+	ErrorClasses  map[ErrorClass]ErrorClassStats // Failures, including HTTP errors, by class
+	Thresholds    []ThresholdResult
+	TargetRPS     float64 // Arrival rate in RPS mode; zero for a closed loop
+	Dropped       int     // RPS mode: iterations skipped because the in-flight cap was reached
+	Late          int     // RPS mode: iterations started well after their scheduled time
+	AbortedBy     string  // Threshold whose breach ended the test early
+	InFlight      int     // Requests being sent right now
+	DataExhausted bool    // The data file ran out, which ended the test
+
+	// Multi-stage tests only
+	Profile     Profile
+	Stages      []StageStats // One per profile stage
+	Stage       int          // Index of the running stage
+	StageTarget float64      // The profile's target right now, in workers or rps
+
+	// Scenarios only
+	Steps []StepStats // One per scenario step
 
 	// Internal tracking for next window
-	windowReqs    int
-	windowLatency time.Duration
+	windowStart    time.Duration
+	windowReqs     int
+	windowFailures int
+	windowHist     *Histogram
+
+	windows    []WindowStats // Ring buffer of the latest maxWindows
+	windowHead int           // Index of the oldest window once the ring is full
+}
+
+// WindowStats aggregates the requests that completed in one second of the test.
+type WindowStats struct {
+	Start    time.Duration // Into the test
+	Duration time.Duration // About a second; the last window may be shorter
+	Requests int
+	Failures int // Of any error class
+	Mean     time.Duration
+	P50      time.Duration
+	P99      time.Duration
+	InFlight int // At the end of the window; the active workers in a closed loop
+}
+
+// RPS returns the completed requests per second in the window.
+func (w WindowStats) RPS() float64 {
+	if w.Duration <= 0 {
+		return 0
+	}
+	return float64(w.Requests) / w.Duration.Seconds()
+}
+
+// ErrorRate returns the share (0-1) of failed requests in the window.
+func (w WindowStats) ErrorRate() float64 {
+	if w.Requests == 0 {
+		return 0
+	}
+	return float64(w.Failures) / float64(w.Requests)
+}
+
+// StageStats are the results of one stage of a profile. Requests count towards
+// the stage in which they started.
+type StageStats struct {
+	Requests int
+	Errors   int // Of any error class
+	Latency  *Histogram
+}
+
+// StepStats are the results of one request of a scenario.
+type StepStats struct {
+	Name        string
+	Requests    int
+	Failures    int // Of any error class
+	StatusCodes map[int]int
+	Latency     *Histogram
 }
 
 // NewStats creates a fresh stats object.
 func NewStats() *Stats {
 	return &Stats{
-		StatusCodes: make(map[int]int),
-		MinLatency:  time.Hour, // large init
+		StatusCodes:  make(map[int]int),
+		Errors:       make(map[string]int),
+		ErrorClasses: make(map[ErrorClass]ErrorClassStats),
+		Latency:      NewHistogram(),
+		windowHist:   NewHistogram(),
+		MinLatency:   time.Hour, // large init
 	}
 }
 
@@ -34,21 +120,200 @@ func (s *Stats) Summary() string {
 	return fmt.Sprintf("Total: %d | Avg: %s | Max: %s", s.TotalRequests, s.AvgLatency, s.MaxLatency)
 }
 
-// P95 calculates the 95th percentile latency (approximate if using points).
-// For MVP we can just sort the huge list if it fits in memory, or keep a sample.
-// We'll trust memory is fine for MVP (<10k requests).
+// RPS returns the completed requests per second over the elapsed time.
+func (s *Stats) RPS() float64 {
+	if s.ElapsedTime <= 0 {
+		return 0
+	}
+	return float64(s.TotalRequests) / s.ElapsedTime.Seconds()
+}
+
+// TransportErrors returns the number of requests that failed without a response.
+func (s *Stats) TransportErrors() int {
+	return s.StatusCodes[0]
+}
+
+// P95 calculates the 95th percentile latency.
 func (s *Stats) P95() time.Duration {
-	if len(s.LatencyPoints) == 0 {
+	return s.Percentile(95)
+}
+
+// Percentile returns the p-th percentile (0-100) latency.
+func (s *Stats) Percentile(p float64) time.Duration {
+	return s.Latency.Percentile(p)
+}
+
+// StagePercentile returns the p-th percentile latency of stage i.
+func (s *Stats) StagePercentile(i int, p float64) time.Duration {
+	return s.Stages[i].Latency.Percentile(p)
+}
+
+// WindowRange is the spread of a per-second metric over the windows of a test.
+type WindowRange struct {
+	Min, Mean, Max float64
+}
+
+// SummarizeWindows returns the range of value over windows.
+func SummarizeWindows(windows []WindowStats, value func(WindowStats) float64) WindowRange {
+	if len(windows) == 0 {
+		return WindowRange{}
+	}
+	r := WindowRange{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, w := range windows {
+		v := value(w)
+		r.Min, r.Max = min(r.Min, v), max(r.Max, v)
+		r.Mean += v
+	}
+	r.Mean /= float64(len(windows))
+	return r
+}
+
+// Windows returns the per-second windows, oldest first.
+func (s *Stats) Windows() []WindowStats {
+	windows := make([]WindowStats, 0, len(s.windows))
+	windows = append(windows, s.windows[s.windowHead:]...)
+	return append(windows, s.windows[:s.windowHead]...)
+}
+
+// Failures returns the number of failed requests of any error class.
+func (s *Stats) Failures() int {
+	n := 0
+	for _, c := range s.ErrorClasses {
+		n += c.Count
+	}
+	return n
+}
+
+// windowDue reports whether the current window is a second old, elapsed into the test.
+func (s *Stats) windowDue(elapsed time.Duration) bool {
+	return elapsed-s.windowStart >= time.Second
+}
+
+// closeWindow ends the current window elapsed into the test, with inFlight requests
+// still running, and starts the next. final closes the last, possibly partial, one.
+func (s *Stats) closeWindow(elapsed time.Duration, inFlight int, final bool) {
+	w := WindowStats{
+		Start:    s.windowStart,
+		Duration: elapsed - s.windowStart,
+		Requests: s.windowReqs,
+		Failures: s.windowFailures,
+		P50:      s.windowHist.Percentile(50),
+		P99:      s.windowHist.Percentile(99),
+		Mean:     s.windowHist.Mean(),
+		InFlight: inFlight,
+	}
+	s.windowStart = elapsed
+	s.windowReqs, s.windowFailures = 0, 0
+	s.windowHist = NewHistogram()
+	if final && (w.Requests == 0 || w.Duration < minFinalWindow) {
+		return
+	}
+
+	if len(s.windows) < maxWindows {
+		s.windows = append(s.windows, w)
+		return
+	}
+	s.windows[s.windowHead] = w
+	s.windowHead = (s.windowHead + 1) % maxWindows
+}
+
+// Snapshot returns a deep copy that stays consistent while the test goes on.
+func (s *Stats) Snapshot() *Stats {
+	c := *s
+	c.StatusCodes = maps.Clone(s.StatusCodes)
+	c.Errors = maps.Clone(s.Errors)
+	c.Latency = s.Latency.Clone()
+	c.Thresholds = slices.Clone(s.Thresholds)
+	c.ErrorClasses = maps.Clone(s.ErrorClasses)
+	c.windowHist = s.windowHist.Clone()
+	c.windows = slices.Clone(s.windows)
+	c.Stages = slices.Clone(s.Stages)
+	for i := range c.Stages {
+		c.Stages[i].Latency = s.Stages[i].Latency.Clone()
+	}
+	c.Steps = slices.Clone(s.Steps)
+	for i := range c.Steps {
+		c.Steps[i].StatusCodes = maps.Clone(s.Steps[i].StatusCodes)
+		c.Steps[i].Latency = s.Steps[i].Latency.Clone()
+	}
+	return &c
+}
+
+// StageElapsed returns how much of stage i has run so far.
+func (s *Stats) StageElapsed(i int) time.Duration {
+	var offset time.Duration
+	for _, st := range s.Profile.Stages[:i] {
+		offset += st.Duration
+	}
+	return max(min(s.ElapsedTime-offset, s.Profile.Stages[i].Duration), 0)
+}
+
+// StageRPS returns the requests per second started in stage i, counting those completed.
+func (s *Stats) StageRPS(i int) float64 {
+	elapsed := s.StageElapsed(i)
+	if elapsed <= 0 {
 		return 0
 	}
-	// Copy to sort so we don't mess up plotting order if we need it?
-	// Actually points are chronological for plotting...
-	// We should probably keep a separate list or just sort a copy.
-	sorted := make([]float64, len(s.LatencyPoints))
-	copy(sorted, s.LatencyPoints)
-	sort.Float64s(sorted)
+	return float64(s.Stages[i].Requests) / elapsed.Seconds()
+}
+
+// recordStage counts a request towards stage i.
+func (s *Stats) recordStage(i int, latency time.Duration, failed bool) {
+	st := &s.Stages[i]
+	st.Requests++
+	st.Latency.Record(latency)
+	if failed {
+		st.Errors++
+	}
+}
+
+// recordStep counts a request towards scenario step i.
+func (s *Stats) recordStep(i int, resp model.Response) {
+	st := &s.Steps[i]
+	st.Requests++
+	st.StatusCodes[resp.StatusCode]++
+	st.Latency.Record(resp.TimeTaken)
+	if Classify(resp) != "" {
+		st.Failures++
+	}
+}
+
+// CountAbove returns how many requests took longer than d.
+func (s *Stats) CountAbove(d time.Duration) int {
+	return int(s.Latency.CountAbove(d))
+}
 
-	idx := int(float64(len(sorted)) * 0.95)
-	ms := sorted[idx]
-	return time.Duration(ms * float64(time.Millisecond))
+// FailedThresholds returns the thresholds that currently don't pass.
+func (s *Stats) FailedThresholds() []ThresholdResult {
+	var failed []ThresholdResult
+	for _, t := range s.Thresholds {
+		if !t.Pass {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// recordFailure counts a failed request, elapsed into the test, by class and,
+// for transport errors, by message.
+func (s *Stats) recordFailure(resp model.Response, class ErrorClass, elapsed time.Duration) {
+	var msg string
+	if resp.Error != nil {
+		msg = errorMessage(resp.Error)
+		key := msg
+		if _, ok := s.Errors[key]; !ok && len(s.Errors) >= maxErrorKinds {
+			key = "other"
+		}
+		s.Errors[key]++
+	} else {
+		msg = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+
+	c, seen := s.ErrorClasses[class]
+	if !seen {
+		c.Sample, c.FirstSeen = msg, elapsed
+	}
+	c.Count++
+	s.ErrorClasses[class] = c
+	s.windowFailures++
 }
@@ -0,0 +1,172 @@
+package load
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Threshold is a pass/fail criterion on a load test metric, e.g. "p95<300ms".
+//
+// Metrics: pNN (any percentile, e.g. p95, p99.9), avg, min and max latency (a duration,
+// or a bare number of milliseconds); error_rate (failed requests, HTTP 4xx/5xx included, as
+// a percentage like 1% or a fraction);
+// rps (completed requests per second).
+type Threshold struct {
+	Raw    string // As written
+	Metric string
+	Op     string  // <, <=, >, >=
+	Limit  float64 // Milliseconds for latencies, a fraction for error_rate
+}
+
+// ThresholdResult is a threshold evaluated against the stats so far.
+type ThresholdResult struct {
+	Threshold string  `json:"threshold"`
+	Metric    string  `json:"metric"`
+	Actual    float64 `json:"actual"` // Same unit as the limit
+	Pass      bool    `json:"pass"`
+	// Final means the threshold can no longer pass, however the test continues.
+	Final bool `json:"-"`
+}
+
+// ParseThreshold parses a threshold like "p95<300ms", "error_rate<1%" or "rps>=200".
+func ParseThreshold(s string) (Threshold, error) {
+	raw := strings.TrimSpace(s)
+	i := strings.IndexAny(raw, "<>")
+	if i <= 0 {
+		return Threshold{}, fmt.Errorf("invalid threshold %q (want e.g. p95<300ms)", s)
+	}
+	t := Threshold{Raw: raw, Metric: strings.ToLower(strings.TrimSpace(raw[:i])), Op: raw[i : i+1]}
+	rest := raw[i+1:]
+	if strings.HasPrefix(rest, "=") {
+		t.Op += "="
+		rest = rest[1:]
+	}
+	value := strings.TrimSpace(rest)
+
+	var err error
+	switch {
+	case t.Metric == "error_rate":
+		if pct, ok := strings.CutSuffix(value, "%"); ok {
+			t.Limit, err = strconv.ParseFloat(strings.TrimSpace(pct), 64)
+			t.Limit /= 100
+		} else {
+			t.Limit, err = strconv.ParseFloat(value, 64)
+		}
+	case t.Metric == "rps":
+		t.Limit, err = strconv.ParseFloat(value, 64)
+	case t.Metric == "avg" || t.Metric == "min" || t.Metric == "max" || isPercentile(t.Metric):
+		t.Limit, err = parseMillis(value)
+	default:
+		return Threshold{}, fmt.Errorf("unknown metric %q in threshold %q (want pNN, avg, min, max, error_rate or rps)", t.Metric, s)
+	}
+	if err != nil {
+		return Threshold{}, fmt.Errorf("invalid value in threshold %q: %v", s, err)
+	}
+	return t, nil
+}
+
+// ParseThresholds parses a comma-separated list of thresholds.
+func ParseThresholds(s string) ([]Threshold, error) {
+	var ts []Threshold
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		t, err := ParseThreshold(part)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+func isPercentile(metric string) bool {
+	p, ok := percentileOf(metric)
+	return ok && p > 0 && p <= 100
+}
+
+func percentileOf(metric string) (float64, bool) {
+	rest, ok := strings.CutPrefix(metric, "p")
+	if !ok {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(rest, 64)
+	return p, err == nil
+}
+
+// parseMillis parses a duration ("300ms", "1.5s") or a bare number of milliseconds.
+func parseMillis(s string) (float64, error) {
+	if ms, err := strconv.ParseFloat(s, 64); err == nil {
+		return ms, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return millis(d), nil
+}
+
+// Evaluate checks the threshold against s. maxRequests is the test's request limit
+// (zero if none); knowing it lets more breaches be recognised as final.
+func (t Threshold) Evaluate(s *Stats, maxRequests int) ThresholdResult {
+	r := ThresholdResult{Threshold: t.Raw, Metric: t.Metric}
+	switch {
+	case t.Metric == "error_rate":
+		if s.TotalRequests > 0 {
+			r.Actual = float64(s.Failures()) / float64(s.TotalRequests)
+		}
+		// Enough errors that even all-successful remaining requests can't dilute them
+		if maxRequests > 0 && (t.Op == "<" || t.Op == "<=") {
+			r.Final = !t.compare(float64(s.Failures()) / float64(maxRequests))
+		}
+	case t.Metric == "rps":
+		r.Actual = s.RPS()
+	case t.Metric == "avg":
+		r.Actual = millis(s.AvgLatency)
+	case t.Metric == "min":
+		if s.TotalRequests > 0 {
+			r.Actual = millis(s.MinLatency)
+		}
+		r.Final = s.TotalRequests > 0 && (t.Op == ">" || t.Op == ">=")
+	case t.Metric == "max":
+		r.Actual = millis(s.MaxLatency)
+		r.Final = t.Op == "<" || t.Op == "<="
+	default:
+		p, _ := percentileOf(t.Metric)
+		r.Actual = millis(s.Percentile(p))
+		// Too many slow requests already for the percentile to come back under the limit
+		if maxRequests > 0 && (t.Op == "<" || t.Op == "<=") {
+			allowed := (100 - p) * float64(maxRequests) / 100
+			r.Final = float64(s.CountAbove(time.Duration(t.Limit*float64(time.Millisecond)))) > allowed
+		}
+	}
+	r.Pass = t.compare(r.Actual)
+	r.Final = r.Final && !r.Pass
+	return r
+}
+
+func (t Threshold) compare(actual float64) bool {
+	switch t.Op {
+	case "<":
+		return actual < t.Limit
+	case "<=":
+		return actual <= t.Limit
+	case ">":
+		return actual > t.Limit
+	}
+	return actual >= t.Limit
+}
+
+// FormatActual formats a result's actual value in the unit of its threshold.
+func (r ThresholdResult) FormatActual() string {
+	switch r.Metric {
+	case "error_rate":
+		return fmt.Sprintf("%.2f%%", r.Actual*100)
+	case "rps":
+		return fmt.Sprintf("%.1f", r.Actual)
+	}
+	return time.Duration(r.Actual * float64(time.Millisecond)).Round(time.Microsecond).String()
+}
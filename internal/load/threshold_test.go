@@ -0,0 +1,117 @@
+package load
+
+import (
+	"errors"
+	"lazycurl/internal/model"
+	"testing"
+	"time"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		in     string
+		metric string
+		op     string
+		limit  float64
+	}{
+		{"p95<300ms", "p95", "<", 300},
+		{"p99.9 <= 1.5s", "p99.9", "<=", 1500},
+		{"avg<250", "avg", "<", 250},
+		{"MAX<1s", "max", "<", 1000},
+		{"min>=2ms", "min", ">=", 2},
+		{"error_rate<1%", "error_rate", "<", 0.01},
+		{"error_rate<=0.05", "error_rate", "<=", 0.05},
+		{"rps>200", "rps", ">", 200},
+	}
+	for _, tt := range tests {
+		got, err := ParseThreshold(tt.in)
+		if err != nil {
+			t.Errorf("ParseThreshold(%q): %v", tt.in, err)
+			continue
+		}
+		if got.Metric != tt.metric || got.Op != tt.op || got.Limit != tt.limit {
+			t.Errorf("ParseThreshold(%q) = %s %s %v, want %s %s %v", tt.in, got.Metric, got.Op, got.Limit, tt.metric, tt.op, tt.limit)
+		}
+	}
+}
+
+func TestParseThresholdErrors(t *testing.T) {
+	for _, in := range []string{"", "p95", "<300ms", "p95<fast", "p0<1s", "latency<1s", "error_rate<x%"} {
+		if _, err := ParseThreshold(in); err == nil {
+			t.Errorf("ParseThreshold(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+// statsOf records responses as the runner does.
+func statsOf(resps ...model.Response) *Stats {
+	r := &Runner{Stats: NewStats()}
+	for _, resp := range resps {
+		r.updateStats(resp, time.Second)
+	}
+	return r.Stats
+}
+
+func repeat(resp model.Response, n int) []model.Response {
+	resps := make([]model.Response, n)
+	for i := range resps {
+		resps[i] = resp
+	}
+	return resps
+}
+
+func TestEvaluateErrorRate(t *testing.T) {
+	ok := model.Response{StatusCode: 200, TimeTaken: 10 * time.Millisecond}
+	tests := []struct {
+		name  string
+		resps []model.Response
+		want  float64
+		pass  bool
+	}{
+		{"all ok", repeat(ok, 100), 0, true},
+		{"http 500s", repeat(model.Response{StatusCode: 500, TimeTaken: time.Millisecond}, 100), 1, false},
+		{"http 404s", append(repeat(ok, 90), repeat(model.Response{StatusCode: 404}, 10)...), 0.1, false},
+		{"transport errors", append(repeat(ok, 99), model.Response{Error: errors.New("connection refused")}), 0.01, false},
+	}
+	th, _ := ParseThreshold("error_rate<1%")
+	for _, tt := range tests {
+		got := th.Evaluate(statsOf(tt.resps...), 0)
+		if got.Actual != tt.want || got.Pass != tt.pass {
+			t.Errorf("%s: actual %v, pass %v; want %v, %v", tt.name, got.Actual, got.Pass, tt.want, tt.pass)
+		}
+	}
+}
+
+func TestEvaluateFinal(t *testing.T) {
+	fail := model.Response{StatusCode: 503, TimeTaken: time.Millisecond}
+	ok := model.Response{StatusCode: 200, TimeTaken: time.Millisecond}
+	slow := model.Response{StatusCode: 200, TimeTaken: time.Second}
+	tests := []struct {
+		threshold   string
+		resps       []model.Response
+		maxRequests int
+		final       bool
+	}{
+		// 2 failures out of at most 100 can still be diluted below 5%, 6 can't
+		{"error_rate<5%", repeat(fail, 2), 100, false},
+		{"error_rate<5%", repeat(fail, 6), 100, true},
+		{"error_rate<5%", repeat(fail, 6), 0, false}, // No limit: more requests may follow
+		// p90 allows 10 of 100 above the limit
+		{"p90<100ms", repeat(slow, 10), 100, false},
+		{"p90<100ms", repeat(slow, 11), 100, true},
+		{"max<100ms", repeat(slow, 1), 0, true},
+		{"max<100ms", repeat(ok, 1), 0, false},
+		{"rps>1000", repeat(ok, 1), 100, false}, // Rates can always recover
+	}
+	for _, tt := range tests {
+		th, err := ParseThreshold(tt.threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := statsOf(tt.resps...)
+		s.ElapsedTime = time.Second
+		if got := th.Evaluate(s, tt.maxRequests); got.Final != tt.final {
+			t.Errorf("%s after %d requests (max %d): final %v, want %v", tt.threshold, len(tt.resps), tt.maxRequests, got.Final, tt.final)
+		}
+	}
+}
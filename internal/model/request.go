@@ -1,21 +1,158 @@
 package model
 
-import "net/http"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
 
-// Request represents an HTTP request to be executed by curl.
+// Request represents an HTTP request to be executed by a transport backend.
 type Request struct {
+	ID      string            `json:"id"`             // Stable identity across renames and reorders
+	Name    string            `json:"name,omitempty"` // Display name; falls back to the URL
 	Method  string            `json:"method"`
 	URL     string            `json:"url"`
 	Headers map[string]string `json:"headers"`
 	Body    string            `json:"body"`
+	Backend string            `json:"backend,omitempty"` // Transport backend ("curl", "native"); empty means the default
+	Filter  string            `json:"filter,omitempty"`  // jq expression the response body is shown through
+
+	BodyMode    BodyMode    `json:"body_mode,omitempty"`
+	ContentType string      `json:"content_type,omitempty"` // Raw and binary bodies; a Content-Type header takes precedence
+	Form        []FormField `json:"form,omitempty"`         // Urlencoded and multipart bodies
+	BodyFile    string      `json:"body_file,omitempty"`    // Binary bodies: the file sent as is
+
+	Timeout        time.Duration `json:"timeout,omitempty"`         // Whole request; zero means no limit
+	ConnectTimeout time.Duration `json:"connect_timeout,omitempty"` // Connection phase only; zero means backend default
 }
 
 // NewRequest creates a default request.
 func NewRequest() Request {
 	return Request{
+		ID:      NewID(),
 		Method:  http.MethodGet,
 		URL:     "https://httpbin.org/get",
 		Headers: make(map[string]string),
 		Body:    "",
 	}
 }
+
+// Title returns the name shown in request lists.
+func (r Request) Title() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.URL
+}
+
+// BodyMode is how the body of a request is made up.
+type BodyMode string
+
+const (
+	BodyRaw        BodyMode = ""           // Body as written, sent as ContentType
+	BodyJSON       BodyMode = "json"       // Body as written, sent as application/json
+	BodyURLEncoded BodyMode = "urlencoded" // Form fields, URL-encoded
+	BodyMultipart  BodyMode = "multipart"  // Form fields and files, as multipart/form-data
+	BodyBinary     BodyMode = "binary"     // The contents of BodyFile
+)
+
+// BodyModes lists the body modes in the order the editor cycles through them.
+var BodyModes = []BodyMode{BodyRaw, BodyJSON, BodyURLEncoded, BodyMultipart, BodyBinary}
+
+func (m BodyMode) String() string {
+	if m == BodyRaw {
+		return "raw"
+	}
+	return string(m)
+}
+
+// FormField is a field of a urlencoded or multipart body.
+type FormField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`          // The text, or the path of a file
+	File  bool   `json:"file,omitempty"` // Multipart only: attach the file at Value
+}
+
+// HasBody reports whether the request sends a body.
+func (r Request) HasBody() bool {
+	switch r.BodyMode {
+	case BodyURLEncoded, BodyMultipart:
+		return len(r.Form) > 0
+	case BodyBinary:
+		return r.BodyFile != ""
+	}
+	return r.Body != ""
+}
+
+// BodyContentType returns the Content-Type of the body, used unless a header sets one.
+// It is empty for multipart bodies, whose boundary is only known once encoded.
+func (r Request) BodyContentType() string {
+	switch r.BodyMode {
+	case BodyJSON:
+		return "application/json"
+	case BodyURLEncoded:
+		return "application/x-www-form-urlencoded"
+	case BodyMultipart:
+		return ""
+	case BodyBinary:
+		if r.ContentType == "" {
+			return "application/octet-stream"
+		}
+		return r.ContentType
+	}
+	if r.ContentType == "" {
+		return "application/x-www-form-urlencoded" // Like curl -d
+	}
+	return r.ContentType
+}
+
+// HasHeader reports whether the request sets the header name, in any case.
+func (r Request) HasHeader(name string) bool {
+	for k := range r.Headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeForm encodes fields as x-www-form-urlencoded, in order.
+func EncodeForm(fields []FormField) string {
+	pairs := make([]string, len(fields))
+	for i, f := range fields {
+		pairs[i] = url.QueryEscape(f.Name) + "=" + url.QueryEscape(f.Value)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// FindRequest returns the request of reqs with the given ID or, failing that, the
+// only one with the given name.
+func FindRequest(reqs []Request, nameOrID string) (Request, error) {
+	var matches []Request
+	for _, r := range reqs {
+		if r.ID == nameOrID {
+			return r, nil
+		}
+		if r.Name == nameOrID {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Request{}, fmt.Errorf("no saved request named %q", nameOrID)
+	case 1:
+		return matches[0], nil
+	}
+	return Request{}, fmt.Errorf("%d saved requests are named %q; use the ID instead", len(matches), nameOrID)
+}
+
+// NewID returns a random identifier for a saved item.
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b) // Never fails; crashes the program instead
+	return hex.EncodeToString(b)
+}
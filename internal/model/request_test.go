@@ -0,0 +1,45 @@
+package model
+
+import "testing"
+
+func TestBodyContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{"raw", Request{}, "application/x-www-form-urlencoded"},
+		{"raw with type", Request{ContentType: "text/plain"}, "text/plain"},
+		{"json", Request{BodyMode: BodyJSON, ContentType: "text/plain"}, "application/json"},
+		{"urlencoded", Request{BodyMode: BodyURLEncoded}, "application/x-www-form-urlencoded"},
+		{"multipart", Request{BodyMode: BodyMultipart, ContentType: "text/plain"}, ""},
+		{"binary", Request{BodyMode: BodyBinary}, "application/octet-stream"},
+		{"binary with type", Request{BodyMode: BodyBinary, ContentType: "image/png"}, "image/png"},
+	}
+	for _, tt := range tests {
+		if got := tt.req.BodyContentType(); got != tt.want {
+			t.Errorf("%s: BodyContentType() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHasBody(t *testing.T) {
+	form := []FormField{{Name: "a", Value: "b"}}
+	tests := []struct {
+		name string
+		req  Request
+		want bool
+	}{
+		{"raw", Request{Body: "x"}, true},
+		{"empty raw", Request{}, false},
+		{"form", Request{BodyMode: BodyURLEncoded, Form: form}, true},
+		{"form ignores body", Request{BodyMode: BodyMultipart, Body: "x"}, false},
+		{"binary", Request{BodyMode: BodyBinary, BodyFile: "/tmp/x"}, true},
+		{"binary ignores body", Request{BodyMode: BodyBinary, Body: "x"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.req.HasBody(); got != tt.want {
+			t.Errorf("%s: HasBody() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,30 @@
 package model
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
 
-// Response represents the result of a curl execution.
+// Response represents the result of a request execution.
 type Response struct {
-	StatusCode int               `json:"status_code"`
-	Body       string            `json:"body"`
-	Headers    map[string]string `json:"headers"`
-	TimeTaken  time.Duration     `json:"time_taken"`
-	Error      error             `json:"error,omitempty"`
+	StatusCode   int           `json:"status_code"`
+	Body         string        `json:"body"`
+	Headers      http.Header   `json:"headers"` // Final response headers; repeated headers keep every value
+	TimeTaken    time.Duration `json:"time_taken"`
+	Timing       Timing        `json:"timing"`
+	SizeDownload int64         `json:"size_download"`
+	RemoteIP     string        `json:"remote_ip"`
+	HTTPVersion  string        `json:"http_version"`
+	NumRedirects int           `json:"num_redirects"`
+	Error        error         `json:"error,omitempty"`
+}
+
+// Timing is the phase breakdown of a request.
+// Like curl's --write-out timings, every value is measured from the start of the request.
+type Timing struct {
+	NameLookup    time.Duration `json:"name_lookup"`    // DNS resolved
+	Connect       time.Duration `json:"connect"`        // TCP connection established
+	AppConnect    time.Duration `json:"app_connect"`    // TLS handshake done (zero for plain HTTP)
+	StartTransfer time.Duration `json:"start_transfer"` // First response byte received
+	Total         time.Duration `json:"total"`          // Response fully received
 }
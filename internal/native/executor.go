@@ -0,0 +1,239 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"lazycurl/internal/model"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Executor executes requests with Go's net/http, reusing connections between calls.
+type Executor struct {
+	Client *http.Client
+}
+
+// NewExecutor creates a new native executor.
+func NewExecutor() *Executor {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Load tests hammer a single host; keep enough idle connections around to avoid re-dialing
+	transport.MaxIdleConnsPerHost = 256
+	transport.MaxIdleConns = 1024
+
+	// Per-request connect timeouts travel in the context since the transport is shared
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if timeout, ok := ctx.Value(connectTimeoutKey{}).(time.Duration); ok {
+			d := *dialer
+			d.Timeout = timeout
+			return d.DialContext(ctx, network, addr)
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	return &Executor{
+		Client: &http.Client{
+			Transport: transport,
+			// Behave like curl without -L: report the redirect instead of following it
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// connectTimeoutKey carries model.Request.ConnectTimeout to the dialer.
+type connectTimeoutKey struct{}
+
+// Execute sends the request and reads the whole response.
+// Cancelling ctx aborts the request.
+func (e *Executor) Execute(ctx context.Context, req model.Request) model.Response {
+	parent := ctx
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+	if req.ConnectTimeout > 0 {
+		ctx = context.WithValue(ctx, connectTimeoutKey{}, req.ConnectTimeout)
+	}
+
+	body, contentType, err := requestBody(req)
+	if err != nil {
+		return model.Response{Error: err}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
+	if err != nil {
+		return model.Response{Error: fmt.Errorf("invalid request: %v", err)}
+	}
+	for k, v := range req.Headers {
+		if strings.EqualFold(k, "Host") {
+			httpReq.Host = v // net/http ignores Host in Header
+			continue
+		}
+		httpReq.Header.Set(k, v)
+	}
+	if contentType != "" && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	rec := &traceRecorder{start: time.Now()}
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), rec.trace()))
+
+	httpResp, err := e.Client.Do(httpReq)
+	if err != nil {
+		resp := rec.response()
+		resp.TimeTaken = time.Since(rec.start)
+		resp.Error = requestError(parent, err)
+		return resp
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	resp := rec.response()
+	resp.Timing.Total = time.Since(rec.start)
+	resp.TimeTaken = resp.Timing.Total
+	if parent.Err() != nil {
+		resp.Error = requestError(parent, err)
+		return resp
+	}
+	if err != nil {
+		resp.Error = fmt.Errorf("failed to read response body: %v", err)
+		return resp
+	}
+
+	resp.StatusCode = httpResp.StatusCode
+	resp.Headers = httpResp.Header
+	resp.Body = string(data)
+	resp.SizeDownload = int64(len(data))
+	resp.HTTPVersion = httpVersion(httpResp)
+	return resp
+}
+
+// requestBody encodes the body of req like curl would, returning it with its content type.
+func requestBody(req model.Request) (io.Reader, string, error) {
+	if !req.HasBody() {
+		return nil, "", nil
+	}
+	switch req.BodyMode {
+	case model.BodyURLEncoded:
+		return strings.NewReader(model.EncodeForm(req.Form)), req.BodyContentType(), nil
+	case model.BodyMultipart:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for _, f := range req.Form {
+			if !f.File {
+				w.WriteField(f.Name, f.Value)
+				continue
+			}
+			data, err := os.ReadFile(f.Value)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read form file: %v", err)
+			}
+			part, err := w.CreatePart(filePartHeader(f.Name, f.Value))
+			if err != nil {
+				return nil, "", err
+			}
+			part.Write(data)
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, w.FormDataContentType(), nil
+	case model.BodyBinary:
+		data, err := os.ReadFile(req.BodyFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read body file: %v", err)
+		}
+		return bytes.NewReader(data), req.BodyContentType(), nil
+	}
+	return strings.NewReader(req.Body), req.BodyContentType(), nil
+}
+
+// filePartHeader returns the header of a multipart file part, typed by the file's
+// extension as curl does.
+func filePartHeader(name, path string) textproto.MIMEHeader {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quote(name), quote(filepath.Base(path))))
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h.Set("Content-Type", contentType)
+	return h
+}
+
+// requestError reports cancellation by the caller distinctly from transport failures.
+func requestError(parent context.Context, err error) error {
+	if parent.Err() != nil {
+		return fmt.Errorf("request cancelled: %w", parent.Err())
+	}
+	return fmt.Errorf("request failed: %v", err)
+}
+
+// traceRecorder collects httptrace events into curl-style timings.
+// Dial callbacks can fire on other goroutines, hence the lock.
+type traceRecorder struct {
+	start time.Time
+
+	mu     sync.Mutex
+	timing model.Timing
+	ip     string
+}
+
+func (r *traceRecorder) trace() *httptrace.ClientTrace {
+	mark := func(d *time.Duration) {
+		r.mu.Lock()
+		*d = time.Since(r.start)
+		r.mu.Unlock()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSDone:              func(httptrace.DNSDoneInfo) { mark(&r.timing.NameLookup) },
+		ConnectDone:          func(_, _ string, _ error) { mark(&r.timing.Connect) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { mark(&r.timing.AppConnect) },
+		GotFirstResponseByte: func() { mark(&r.timing.StartTransfer) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Conn == nil {
+				return
+			}
+			host, _, err := net.SplitHostPort(info.Conn.RemoteAddr().String())
+			if err != nil {
+				return
+			}
+			r.mu.Lock()
+			r.ip = host
+			r.mu.Unlock()
+		},
+	}
+}
+
+// response returns a response pre-filled with what has been traced so far.
+func (r *traceRecorder) response() model.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return model.Response{Timing: r.timing, RemoteIP: r.ip}
+}
+
+// httpVersion formats the protocol like curl's %{http_version} ("1.1", "2").
+func httpVersion(resp *http.Response) string {
+	if resp.ProtoMajor >= 2 {
+		return strconv.Itoa(resp.ProtoMajor)
+	}
+	return fmt.Sprintf("%d.%d", resp.ProtoMajor, resp.ProtoMinor)
+}
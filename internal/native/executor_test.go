@@ -0,0 +1,216 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"lazycurl/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echo is what the test server saw of a request.
+type echo struct {
+	Method      string
+	Host        string
+	Header      http.Header
+	Body        string
+	ContentType string
+	Form        map[string][]string
+	Files       map[string]string // Field name -> file name and contents
+}
+
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		e := echo{Method: r.Method, Host: r.Host, Header: r.Header, ContentType: r.Header.Get("Content-Type")}
+		if strings.HasPrefix(e.ContentType, "multipart/") {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			e.Form, e.Files = r.MultipartForm.Value, make(map[string]string)
+			for name, files := range r.MultipartForm.File {
+				f, _ := files[0].Open()
+				data, _ := io.ReadAll(f)
+				f.Close()
+				e.Files[name] = files[0].Filename + ":" + string(data)
+			}
+		} else {
+			data, _ := io.ReadAll(r.Body)
+			e.Body = string(data)
+		}
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		json.NewEncoder(w).Encode(e)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/echo", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func execEcho(t *testing.T, req model.Request) (model.Response, echo) {
+	t.Helper()
+	resp := NewExecutor().Execute(context.Background(), req)
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	var e echo
+	if err := json.Unmarshal([]byte(resp.Body), &e); err != nil {
+		t.Fatalf("status %d, body %q: %v", resp.StatusCode, resp.Body, err)
+	}
+	return resp, e
+}
+
+func TestExecuteHeaders(t *testing.T) {
+	srv := testServer(t)
+	resp, e := execEcho(t, model.Request{
+		Method:  "GET",
+		URL:     srv.URL + "/echo",
+		Headers: map[string]string{"host": "api.example.com", "X-Trace": "abc", "Authorization": "Bearer t"},
+	})
+	if e.Host != "api.example.com" {
+		t.Errorf("server saw Host %q, want api.example.com", e.Host)
+	}
+	if e.Header.Get("X-Trace") != "abc" || e.Header.Get("Authorization") != "Bearer t" {
+		t.Errorf("server saw headers %v", e.Header)
+	}
+	if e.Method != "GET" || e.Body != "" || e.ContentType != "" {
+		t.Errorf("bodiless GET arrived as %s with body %q, type %q", e.Method, e.Body, e.ContentType)
+	}
+	if got := resp.Headers.Values("Set-Cookie"); len(got) != 2 {
+		t.Errorf("Set-Cookie = %q, want both values", got)
+	}
+	if resp.StatusCode != 200 || resp.HTTPVersion != "1.1" || resp.RemoteIP != "127.0.0.1" || resp.SizeDownload != int64(len(resp.Body)) {
+		t.Errorf("status %d, version %q, ip %q, size %d", resp.StatusCode, resp.HTTPVersion, resp.RemoteIP, resp.SizeDownload)
+	}
+}
+
+func TestExecuteBodies(t *testing.T) {
+	srv := testServer(t)
+	file := filepath.Join(t.TempDir(), "data.json")
+	os.WriteFile(file, []byte(`{"from":"file"}`), 0o644)
+
+	tests := []struct {
+		name     string
+		req      model.Request
+		body     string
+		wantType string
+	}{
+		{"raw", model.Request{Body: "@not-a-file"}, "@not-a-file", "application/x-www-form-urlencoded"},
+		{"raw with type", model.Request{Body: "hi", ContentType: "text/plain"}, "hi", "text/plain"},
+		{"json", model.Request{BodyMode: model.BodyJSON, Body: `{"a":1}`}, `{"a":1}`, "application/json"},
+		{"header wins", model.Request{BodyMode: model.BodyJSON, Body: "{}", Headers: map[string]string{"content-type": "application/vnd.x+json"}}, "{}", "application/vnd.x+json"},
+		{"urlencoded", model.Request{BodyMode: model.BodyURLEncoded, Form: []model.FormField{{Name: "q", Value: "a b&c"}, {Name: "n", Value: "@x"}}}, "q=a+b%26c&n=%40x", "application/x-www-form-urlencoded"},
+		{"binary", model.Request{BodyMode: model.BodyBinary, BodyFile: file}, `{"from":"file"}`, "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		tt.req.Method, tt.req.URL = "POST", srv.URL+"/echo"
+		_, e := execEcho(t, tt.req)
+		if e.Body != tt.body || e.ContentType != tt.wantType {
+			t.Errorf("%s: server got %q as %q, want %q as %q", tt.name, e.Body, e.ContentType, tt.body, tt.wantType)
+		}
+	}
+}
+
+func TestExecuteMultipart(t *testing.T) {
+	srv := testServer(t)
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(file, []byte("file contents"), 0o644)
+
+	_, e := execEcho(t, model.Request{
+		Method:   "POST",
+		URL:      srv.URL + "/echo",
+		BodyMode: model.BodyMultipart,
+		Form: []model.FormField{
+			{Name: "note", Value: `a;b "c"`},
+			{Name: "handle", Value: "@not-a-file"},
+			{Name: "upload", Value: file, File: true},
+		},
+	})
+	if got := e.Form["note"]; len(got) != 1 || got[0] != `a;b "c"` {
+		t.Errorf("note = %q", got)
+	}
+	if got := e.Form["handle"]; len(got) != 1 || got[0] != "@not-a-file" {
+		t.Errorf("handle = %q", got)
+	}
+	if e.Files["upload"] != "notes.txt:file contents" {
+		t.Errorf("upload = %q", e.Files["upload"])
+	}
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	srv := testServer(t)
+	start := time.Now()
+	resp := NewExecutor().Execute(context.Background(), model.Request{Method: "GET", URL: srv.URL + "/slow", Timeout: 100 * time.Millisecond})
+	if resp.Error == nil || errors.Is(resp.Error, context.Canceled) {
+		t.Errorf("error = %v, want a timeout failure", resp.Error)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("timed out after %s", elapsed)
+	}
+}
+
+func TestExecuteConnectTimeout(t *testing.T) {
+	// Nothing answers on TEST-NET-1, so the connect can only fail or time out
+	start := time.Now()
+	resp := NewExecutor().Execute(context.Background(), model.Request{Method: "GET", URL: "http://192.0.2.1/", ConnectTimeout: 100 * time.Millisecond})
+	if resp.Error == nil {
+		t.Error("connect to an unreachable address succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("connect gave up after %s", elapsed)
+	}
+}
+
+func TestExecuteCancel(t *testing.T) {
+	srv := testServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	resp := NewExecutor().Execute(ctx, model.Request{Method: "GET", URL: srv.URL + "/slow"})
+	if !errors.Is(resp.Error, context.Canceled) {
+		t.Errorf("error = %v, want cancellation", resp.Error)
+	}
+}
+
+func TestExecuteDoesNotFollowRedirects(t *testing.T) {
+	srv := testServer(t)
+	resp := NewExecutor().Execute(context.Background(), model.Request{Method: "GET", URL: srv.URL + "/redirect"})
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	if resp.StatusCode != http.StatusFound || resp.Headers.Get("Location") != "/echo" {
+		t.Errorf("status %d, location %q; want the redirect itself", resp.StatusCode, resp.Headers.Get("Location"))
+	}
+}
+
+func TestExecuteTimings(t *testing.T) {
+	srv := testServer(t)
+	resp, _ := execEcho(t, model.Request{Method: "GET", URL: srv.URL + "/echo"})
+	tm := resp.Timing
+	if tm.Connect <= 0 || tm.StartTransfer < tm.Connect || tm.Total < tm.StartTransfer {
+		t.Errorf("timings out of order: %+v", tm)
+	}
+	if tm.AppConnect != 0 {
+		t.Errorf("plain HTTP has a TLS handshake time: %s", tm.AppConnect)
+	}
+	if resp.TimeTaken != tm.Total {
+		t.Errorf("time taken %s, want the total %s", resp.TimeTaken, tm.Total)
+	}
+}
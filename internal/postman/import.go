@@ -0,0 +1,373 @@
+package postman
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"lazycurl/internal/env"
+	"lazycurl/internal/model"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Result is what an import produced.
+type Result struct {
+	Requests     []model.Request
+	Environments []env.Environment
+	Unsupported  []string // Items that were skipped or only partly imported
+}
+
+// ImportFiles imports a collection file and any number of environment files.
+func ImportFiles(collectionPath string, environmentPaths []string) (*Result, error) {
+	data, err := os.ReadFile(collectionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collection: %v", err)
+	}
+	c, err := ParseCollection(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", collectionPath, err)
+	}
+
+	var envs []*Environment
+	for _, path := range environmentPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read environment: %v", err)
+		}
+		e, err := ParseEnvironment(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		envs = append(envs, e)
+	}
+
+	return Import(c, envs), nil
+}
+
+// ParseCollection decodes a Postman collection, rejecting formats other than v2.x.
+func ParseCollection(data []byte) (*Collection, error) {
+	var c Collection
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("not a valid Postman collection: %v", err)
+	}
+	if c.Info.Schema != "" && !strings.Contains(c.Info.Schema, "/v2.") {
+		return nil, fmt.Errorf("unsupported collection schema %q (export as Collection v2.1)", c.Info.Schema)
+	}
+	if c.Info.Name == "" && len(c.Items) == 0 {
+		return nil, fmt.Errorf("not a Postman collection (no info or items)")
+	}
+	return &c, nil
+}
+
+// ParseEnvironment decodes an exported Postman environment.
+func ParseEnvironment(data []byte) (*Environment, error) {
+	var e Environment
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("not a valid Postman environment: %v", err)
+	}
+	if e.Name == "" {
+		return nil, fmt.Errorf("not a Postman environment (no name)")
+	}
+	return &e, nil
+}
+
+// Import maps a collection and its environments onto lazycurl requests and environments.
+// Collection variables become an environment named after the collection; each Postman
+// environment becomes an environment holding the collection variables overridden by its own.
+func Import(c *Collection, envs []*Environment) *Result {
+	r := &Result{}
+
+	collectionVars := make(map[string]string)
+	for _, v := range c.Variable {
+		if !v.Disabled && v.Key != "" {
+			collectionVars[v.Key] = valueString(v.Value)
+		}
+	}
+
+	name := c.Info.Name
+	if name == "" {
+		name = "postman"
+	}
+	r.unsupportedEvents(name, c.Event)
+	r.importItems(c.Items, nil, c.Auth)
+
+	if len(collectionVars) > 0 {
+		r.Environments = append(r.Environments, env.Environment{Name: name, Vars: collectionVars})
+	}
+	for _, e := range envs {
+		vars := make(map[string]string, len(collectionVars)+len(e.Values))
+		for k, v := range collectionVars {
+			vars[k] = v
+		}
+		for _, v := range e.Values {
+			if v.Key != "" && (v.Enabled == nil || *v.Enabled) {
+				vars[v.Key] = valueString(v.Value)
+			}
+		}
+		r.Environments = append(r.Environments, env.Environment{Name: e.Name, Vars: vars})
+	}
+
+	return r
+}
+
+// Summary describes the import in a few lines, listing everything that wasn't imported faithfully.
+func (r *Result) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Imported %d requests and %d environments", len(r.Requests), len(r.Environments))
+	if len(r.Unsupported) > 0 {
+		fmt.Fprintf(&sb, "; %d unsupported:", len(r.Unsupported))
+		for _, u := range r.Unsupported {
+			sb.WriteString("\n  - " + u)
+		}
+	}
+	return sb.String()
+}
+
+// MergeInto appends the imported requests to reqs and adds the imported environments to set,
+// replacing environments of the same name. If no environment is active, the last imported
+// one (a Postman environment if any were given) becomes active.
+func (r *Result) MergeInto(reqs []model.Request, set *env.Set) []model.Request {
+	reqs = append(reqs, r.Requests...)
+	for _, imported := range r.Environments {
+		e := set.Upsert(imported.Name)
+		e.Vars = imported.Vars
+	}
+	if set.Active == "" && len(r.Environments) > 0 {
+		set.Active = r.Environments[len(r.Environments)-1].Name
+	}
+	return reqs
+}
+
+func (r *Result) importItems(items []Item, path []string, auth *Auth) {
+	for _, item := range items {
+		itemPath := append(append([]string{}, path...), item.Name)
+		title := strings.Join(itemPath, " / ")
+		r.unsupportedEvents(title, item.Event)
+
+		if item.Request == nil {
+			// Folder
+			itemAuth := auth
+			if item.Auth != nil {
+				itemAuth = item.Auth
+			}
+			r.importItems(item.Items, itemPath, itemAuth)
+			continue
+		}
+
+		reqAuth := auth
+		if item.Request.Auth != nil {
+			reqAuth = item.Request.Auth
+		}
+		r.Requests = append(r.Requests, r.mapRequest(title, *item.Request, reqAuth))
+	}
+}
+
+func (r *Result) mapRequest(title string, p Request, auth *Auth) model.Request {
+	req := model.NewRequest()
+	req.Name = title
+	req.Method = strings.ToUpper(p.Method)
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	req.URL = p.URL.String()
+
+	for _, h := range p.Header {
+		if !h.Disabled && h.Key != "" {
+			req.Headers[h.Key] = h.Value
+		}
+	}
+
+	if p.Body != nil && !p.Body.Disabled {
+		r.mapBody(title, &req, p.Body)
+	}
+	if auth != nil {
+		r.mapAuth(title, &req, auth)
+	}
+	return req
+}
+
+func (r *Result) mapBody(title string, req *model.Request, b *Body) {
+	switch b.Mode {
+	case "", "none":
+	case "raw":
+		req.Body = b.Raw
+		// Postman sends raw bodies as plain text unless a language says otherwise
+		lang := "text"
+		if b.Options != nil && b.Options.Raw != nil && b.Options.Raw.Language != "" {
+			lang = strings.ToLower(b.Options.Raw.Language)
+		}
+		if lang == "json" {
+			req.BodyMode = model.BodyJSON
+		} else if ct, ok := rawContentTypes[lang]; ok {
+			req.ContentType = ct
+		} else {
+			req.ContentType = rawContentTypes["text"]
+		}
+	case "urlencoded":
+		req.BodyMode = model.BodyURLEncoded
+		for _, f := range b.URLEncoded {
+			if !f.Disabled {
+				req.Form = append(req.Form, model.FormField{Name: f.Key, Value: f.Value})
+			}
+		}
+	case "formdata":
+		req.BodyMode = model.BodyMultipart
+		for _, f := range b.FormData {
+			if f.Disabled {
+				continue
+			}
+			if f.Type != "file" {
+				req.Form = append(req.Form, model.FormField{Name: f.Key, Value: f.Value})
+				continue
+			}
+			paths := srcPaths(f.Src)
+			if len(paths) == 0 {
+				r.Unsupported = append(r.Unsupported, fmt.Sprintf("%s: form-data file field %q has no file", title, f.Key))
+			}
+			for _, path := range paths {
+				req.Form = append(req.Form, model.FormField{Name: f.Key, Value: path, File: true})
+			}
+		}
+	case "file":
+		if b.File == nil || b.File.Src == "" {
+			r.Unsupported = append(r.Unsupported, fmt.Sprintf("%s: file body has no file", title))
+			return
+		}
+		req.BodyMode, req.BodyFile = model.BodyBinary, b.File.Src
+	case "graphql":
+		if b.GraphQL != nil {
+			payload := map[string]any{"query": b.GraphQL.Query}
+			if vars := strings.TrimSpace(b.GraphQL.Variables); vars != "" {
+				payload["variables"] = json.RawMessage(vars)
+			}
+			if data, err := json.Marshal(payload); err == nil {
+				req.Body = string(data)
+			} else {
+				r.Unsupported = append(r.Unsupported, fmt.Sprintf("%s: invalid GraphQL variables", title))
+			}
+		}
+		req.BodyMode = model.BodyJSON
+	default:
+		r.Unsupported = append(r.Unsupported, fmt.Sprintf("%s: %s body not supported", title, b.Mode))
+	}
+}
+
+func (r *Result) mapAuth(title string, req *model.Request, a *Auth) {
+	param := func(params []KeyValue, key string) string {
+		for _, p := range params {
+			if p.Key == key {
+				return p.Value
+			}
+		}
+		return ""
+	}
+
+	switch a.Type {
+	case "", "noauth", "inherit":
+	case "basic":
+		user, pass := param(a.Basic, "username"), param(a.Basic, "password")
+		if strings.Contains(user+pass, "{{") {
+			r.Unsupported = append(r.Unsupported, fmt.Sprintf("%s: basic auth credentials use variables, which are encoded literally", title))
+		}
+		creds := base64.StdEncoding.EncodeToString([]byte(user + ":" + pass))
+		setDefaultHeader(req, "Authorization", "Basic "+creds)
+	case "bearer":
+		setDefaultHeader(req, "Authorization", "Bearer "+param(a.Bearer, "token"))
+	case "apikey":
+		k, v := param(a.APIKey, "key"), param(a.APIKey, "value")
+		if param(a.APIKey, "in") == "query" {
+			sep := "?"
+			if strings.Contains(req.URL, "?") {
+				sep = "&"
+			}
+			req.URL += sep + escapeKeepingVars(k) + "=" + escapeKeepingVars(v)
+		} else {
+			setDefaultHeader(req, k, v)
+		}
+	default:
+		r.Unsupported = append(r.Unsupported, fmt.Sprintf("%s: %s auth not supported", title, a.Type))
+	}
+}
+
+func (r *Result) unsupportedEvents(title string, events []Event) {
+	for _, e := range events {
+		if !e.Disabled {
+			r.Unsupported = append(r.Unsupported, fmt.Sprintf("%s: %s script ignored", title, e.Listen))
+		}
+	}
+}
+
+// rawContentTypes maps Postman's raw body language to a Content-Type.
+var rawContentTypes = map[string]string{
+	"json":       "application/json",
+	"xml":        "application/xml",
+	"html":       "text/html",
+	"javascript": "application/javascript",
+	"text":       "text/plain",
+}
+
+// setDefaultHeader sets a header unless the request already has it (in any case) or value is empty.
+func setDefaultHeader(req *model.Request, key, value string) {
+	if value == "" {
+		return
+	}
+	for k := range req.Headers {
+		if strings.EqualFold(k, key) {
+			return
+		}
+	}
+	req.Headers[key] = value
+}
+
+// srcPaths returns the file paths of a form-data file field: one, or a list.
+func srcPaths(src any) []string {
+	switch src := src.(type) {
+	case string:
+		if src != "" {
+			return []string{src}
+		}
+	case []any:
+		var paths []string
+		for _, p := range src {
+			if p, ok := p.(string); ok && p != "" {
+				paths = append(paths, p)
+			}
+		}
+		return paths
+	}
+	return nil
+}
+
+// escapeKeepingVars query-escapes s but leaves {{var}} references intact so they still resolve.
+func escapeKeepingVars(s string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start + 2
+		sb.WriteString(url.QueryEscape(s[:start]))
+		sb.WriteString(s[start:end])
+		s = s[end:]
+	}
+	sb.WriteString(url.QueryEscape(s))
+	return sb.String()
+}
+
+func valueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
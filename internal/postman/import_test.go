@@ -0,0 +1,33 @@
+package postman
+
+import (
+	"encoding/json"
+	"lazycurl/internal/model"
+	"testing"
+)
+
+func TestMapRawBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		mode        model.BodyMode
+		contentType string
+	}{
+		{"json", `{"mode":"raw","raw":"{}","options":{"raw":{"language":"json"}}}`, model.BodyJSON, "application/json"},
+		{"xml", `{"mode":"raw","raw":"<a/>","options":{"raw":{"language":"xml"}}}`, model.BodyRaw, "application/xml"},
+		{"text", `{"mode":"raw","raw":"hi","options":{"raw":{"language":"text"}}}`, model.BodyRaw, "text/plain"},
+		{"no language", `{"mode":"raw","raw":"hi"}`, model.BodyRaw, "text/plain"},
+		{"unknown language", `{"mode":"raw","raw":"hi","options":{"raw":{"language":"yaml"}}}`, model.BodyRaw, "text/plain"},
+	}
+	for _, tt := range tests {
+		var b Body
+		if err := json.Unmarshal([]byte(tt.body), &b); err != nil {
+			t.Fatal(err)
+		}
+		var req model.Request
+		(&Result{}).mapBody(tt.name, &req, &b)
+		if req.BodyMode != tt.mode || req.BodyContentType() != tt.contentType {
+			t.Errorf("%s: mode %q, content type %q; want %q, %q", tt.name, req.BodyMode, req.BodyContentType(), tt.mode, tt.contentType)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package postman
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Collection is the subset of the Postman Collection v2.1 format lazycurl understands.
+// Schema: https://schema.postman.com/collection/json/v2.1.0/draft-07/collection.json
+type Collection struct {
+	Info     Info       `json:"info"`
+	Items    []Item     `json:"item"`
+	Variable []Variable `json:"variable"`
+	Auth     *Auth      `json:"auth"`
+	Event    []Event    `json:"event"`
+}
+
+// Info describes the collection.
+type Info struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// Item is either a folder (Items set) or a request (Request set).
+type Item struct {
+	Name    string   `json:"name"`
+	Items   []Item   `json:"item"`
+	Request *Request `json:"request"`
+	Auth    *Auth    `json:"auth"` // Folder-level auth, inherited by its requests
+	Event   []Event  `json:"event"`
+}
+
+// Request is a Postman request. In the file it may also be a bare URL string.
+type Request struct {
+	Method string     `json:"method"`
+	Header []KeyValue `json:"header"`
+	Body   *Body      `json:"body"`
+	URL    URL        `json:"url"`
+	Auth   *Auth      `json:"auth"`
+}
+
+// UnmarshalJSON accepts both the object form and the bare URL string form.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*r = Request{Method: "GET", URL: URL{Raw: raw}}
+		return nil
+	}
+	type plain Request
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// URL is a Postman URL. In the file it may be a string or a structured object.
+type URL struct {
+	Raw      string     `json:"raw"`
+	Protocol string     `json:"protocol"`
+	Host     []string   `json:"host"`
+	Path     []string   `json:"path"`
+	Query    []KeyValue `json:"query"`
+}
+
+// UnmarshalJSON accepts both the string and the object form.
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*u = URL{Raw: raw}
+		return nil
+	}
+
+	// host and path may themselves be strings instead of arrays
+	var obj struct {
+		Raw      string          `json:"raw"`
+		Protocol string          `json:"protocol"`
+		Host     json.RawMessage `json:"host"`
+		Path     json.RawMessage `json:"path"`
+		Query    []KeyValue      `json:"query"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*u = URL{Raw: obj.Raw, Protocol: obj.Protocol, Query: obj.Query}
+	u.Host = stringOrList(obj.Host, ".")
+	u.Path = stringOrList(obj.Path, "/")
+	return nil
+}
+
+// String returns the full URL, preferring the raw form.
+func (u URL) String() string {
+	if u.Raw != "" {
+		return u.Raw
+	}
+
+	s := strings.Join(u.Host, ".")
+	if u.Protocol != "" {
+		s = u.Protocol + "://" + s
+	}
+	if len(u.Path) > 0 {
+		s += "/" + strings.Join(u.Path, "/")
+	}
+
+	var query []string
+	for _, q := range u.Query {
+		if !q.Disabled {
+			query = append(query, q.Key+"="+q.Value)
+		}
+	}
+	if len(query) > 0 {
+		s += "?" + strings.Join(query, "&")
+	}
+	return s
+}
+
+func stringOrList(data json.RawMessage, sep string) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil && s != "" {
+		return strings.Split(s, sep)
+	}
+	return nil
+}
+
+// KeyValue is a header, query parameter or urlencoded/form-data field.
+type KeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"` // form-data only: "text" or "file"
+	Src      any    `json:"src"`  // form-data file path(s)
+}
+
+// Body is a request body.
+type Body struct {
+	Mode       string     `json:"mode"` // raw, urlencoded, formdata, file, graphql
+	Raw        string     `json:"raw"`
+	URLEncoded []KeyValue `json:"urlencoded"`
+	FormData   []KeyValue `json:"formdata"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options *struct {
+		Raw *struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+	File *struct {
+		Src string `json:"src"` // Path of the file sent as the body
+	} `json:"file"`
+	Disabled bool `json:"disabled"`
+}
+
+// Auth is an auth block; the parameters live under the key named by Type.
+type Auth struct {
+	Type   string     `json:"type"`
+	Basic  []KeyValue `json:"basic"`
+	Bearer []KeyValue `json:"bearer"`
+	APIKey []KeyValue `json:"apikey"`
+}
+
+// Event is a pre-request or test script.
+type Event struct {
+	Listen   string `json:"listen"`
+	Disabled bool   `json:"disabled"`
+}
+
+// Variable is a collection variable.
+type Variable struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+// Environment is an exported Postman environment.
+type Environment struct {
+	Name   string `json:"name"`
+	Values []struct {
+		Key     string `json:"key"`
+		Value   any    `json:"value"`
+		Enabled *bool  `json:"enabled"` // Missing means enabled
+	} `json:"values"`
+}
@@ -0,0 +1,189 @@
+package runs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"lazycurl/internal/load"
+	"lazycurl/internal/model"
+	"lazycurl/internal/workspace"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dir is the directory inside a workspace holding one JSON file per run.
+const Dir = "runs"
+
+// Run is a finished load test, kept so it can be compared with later ones.
+type Run struct {
+	ID      string            `json:"id"`
+	Time    time.Time         `json:"time"`    // When the test started
+	Request model.Request     `json:"request"` // As sent, with variables resolved; empty for a scenario
+	Env     string            `json:"env,omitempty"`
+	Vars    map[string]string `json:"vars,omitempty"` // Of the environment, at the time
+	Config  Config            `json:"config"`
+	Report  load.Report       `json:"report"`
+
+	Scenario []load.ScenarioStep `json:"scenario,omitempty"` // Sent instead of Request
+}
+
+// Config holds the settings a test ran with.
+type Config struct {
+	Concurrency int           `json:"concurrency"` // Workers, or the most in flight with a rate
+	Duration    time.Duration `json:"duration"`
+	MaxRequests int           `json:"max_requests,omitempty"`
+	RPS         float64       `json:"rps,omitempty"`
+	Stages      string        `json:"stages,omitempty"`
+	Thresholds  []string      `json:"thresholds,omitempty"`
+	AbortOnFail bool          `json:"abort_on_fail,omitempty"`
+	Scenario    string        `json:"scenario,omitempty"` // As written, e.g. "List=70, Get=30"
+
+	DataFile     string            `json:"data_file,omitempty"` // Feeding {{variables}} per iteration
+	DataStrategy load.FeedStrategy `json:"data_strategy,omitempty"`
+	DataEnd      load.FeedEnd      `json:"data_end,omitempty"`
+}
+
+// NewRun records a test of req, started at start, that ended with stats s.
+func NewRun(start time.Time, req model.Request, env string, vars map[string]string, config Config, s *load.Stats) Run {
+	return Run{
+		ID:      model.NewID(),
+		Time:    start,
+		Request: req,
+		Env:     env,
+		Vars:    vars,
+		Config:  config,
+		Report:  load.NewReport(s),
+	}
+}
+
+// Title names what the run tested, e.g. "GET Users" or "scenario List=70, Get=30".
+func (r Run) Title() string {
+	if len(r.Scenario) > 0 {
+		return "scenario " + r.Config.Scenario
+	}
+	return r.Request.Method + " " + r.Request.Title()
+}
+
+// Describe summarises the load the run was configured with, e.g. "10 workers for 5s".
+func (c Config) Describe() string {
+	var desc string
+	switch {
+	case c.Stages != "":
+		desc = "stages " + c.Stages
+	case c.RPS > 0:
+		desc = fmt.Sprintf("%g rps, at most %d in flight", c.RPS, c.Concurrency)
+	default:
+		desc = fmt.Sprintf("%d workers", c.Concurrency)
+	}
+	if c.Stages == "" && c.Duration > 0 {
+		desc += " for " + c.Duration.String()
+	}
+	if c.MaxRequests > 0 {
+		desc += fmt.Sprintf(", %d requests", c.MaxRequests)
+	}
+	if c.DataFile != "" {
+		desc += fmt.Sprintf(", data %s (%s)", filepath.Base(c.DataFile), load.DescribeFeed(c.DataStrategy, c.DataEnd))
+	}
+	if len(c.Thresholds) > 0 {
+		desc += ", thresholds " + strings.Join(c.Thresholds, ", ")
+	}
+	return desc
+}
+
+// Metric is a figure two runs are compared by.
+type Metric struct {
+	Name          string
+	LowerIsBetter bool
+	Value         func(load.Report) float64
+}
+
+// Metrics lists the figures of the compare view, in display order.
+var Metrics = []Metric{
+	{"Requests", false, func(r load.Report) float64 { return float64(r.Requests) }},
+	{"RPS", false, func(r load.Report) float64 { return r.RPS }},
+	{"Error rate %", true, func(r load.Report) float64 { return 100 * failureRate(r) }},
+	{"Mean (ms)", true, func(r load.Report) float64 { return r.LatencyMS.Mean }},
+	{"p50 (ms)", true, func(r load.Report) float64 { return r.LatencyMS.P50 }},
+	{"p90 (ms)", true, func(r load.Report) float64 { return r.LatencyMS.P90 }},
+	{"p95 (ms)", true, func(r load.Report) float64 { return r.LatencyMS.P95 }},
+	{"p99 (ms)", true, func(r load.Report) float64 { return r.LatencyMS.P99 }},
+	{"p99.9 (ms)", true, func(r load.Report) float64 { return r.LatencyMS.P999 }},
+	{"Max (ms)", true, func(r load.Report) float64 { return r.LatencyMS.Max }},
+}
+
+// failureRate returns the share (0-1) of requests that failed, HTTP errors included.
+func failureRate(r load.Report) float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Failures) / float64(r.Requests)
+}
+
+// Store keeps runs in a directory, one file each.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store backed by the directory at dir, created on first save.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save writes a run.
+func (s *Store) Save(r Run) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", s.dir, err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Keep thresholds like "p95<300ms" readable in the file
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode run: %v", err)
+	}
+	return workspace.WriteFileAtomic(s.path(r.ID), buf.Bytes())
+}
+
+// Load returns all runs, newest first. Files that don't parse are skipped.
+func (s *Store) Load() ([]Run, error) {
+	files, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runs: %v", err)
+	}
+
+	var runs []Run
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		var r Run
+		if err := json.Unmarshal(data, &r); err != nil || r.ID == "" {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Time.After(runs[j].Time) })
+	return runs, nil
+}
+
+// Delete removes a run.
+func (s *Store) Delete(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete run: %v", err)
+	}
+	return nil
+}
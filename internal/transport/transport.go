@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"lazycurl/internal/curl"
+	"lazycurl/internal/model"
+	"lazycurl/internal/native"
+	"strings"
+	"sync"
+)
+
+// Backend names accepted in model.Request.Backend and the --backend flags.
+const (
+	Curl   = "curl"
+	Native = "native"
+)
+
+// Backends lists the available backends, default first.
+var Backends = []string{Curl, Native}
+
+// Executor executes a request and reports the result.
+// Every backend yields the same model.Response shape so views and stats don't care which one ran.
+// Cancelling ctx aborts the request; the response error then wraps ctx.Err().
+type Executor interface {
+	Execute(ctx context.Context, req model.Request) model.Response
+}
+
+// New creates the backend with the given name. An empty name selects curl.
+func New(name string) (Executor, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", Curl:
+		return curl.NewExecutor(), nil
+	case Native:
+		return native.NewExecutor(), nil
+	}
+	return nil, fmt.Errorf("unknown backend %q (want one of: %s)", name, strings.Join(Backends, ", "))
+}
+
+// Mux dispatches each request to the backend named in Request.Backend.
+// Backends are created once and reused so the native backend keeps its connection pool.
+type Mux struct {
+	Default string
+
+	mu        sync.Mutex
+	executors map[string]Executor
+}
+
+// NewMux creates a mux whose requests default to the given backend.
+func NewMux(defaultBackend string) *Mux {
+	return &Mux{
+		Default:   defaultBackend,
+		executors: make(map[string]Executor),
+	}
+}
+
+// Execute runs the request on its backend.
+func (m *Mux) Execute(ctx context.Context, req model.Request) model.Response {
+	exec, err := m.executor(req.Backend)
+	if err != nil {
+		return model.Response{Error: err}
+	}
+	return exec.Execute(ctx, req)
+}
+
+func (m *Mux) executor(name string) (Executor, error) {
+	if name == "" {
+		name = m.Default
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if exec, ok := m.executors[name]; ok {
+		return exec, nil
+	}
+	exec, err := New(name)
+	if err != nil {
+		return nil, err
+	}
+	m.executors[name] = exec
+	return exec, nil
+}
@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	// JSON syntax colours
+	jsonKeyStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("75"))  // Blue
+	jsonStringStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("114")) // Green
+	jsonNumberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("215")) // Orange
+	jsonLiteralStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205")) // Pink
+	jsonPunctStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("245")) // Grey
+	lineNumberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	filterErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")) // Red
+)
+
+// formatBody renders a response body for the viewer: JSON is pretty-printed and coloured
+// unless raw is set. Every line is prefixed with its line number.
+func formatBody(body string, headers http.Header, raw bool) string {
+	if body == "" {
+		return labelStyle.Render("Empty body.")
+	}
+	if !raw && isJSON(body, headers) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(body), "", "  "); err == nil {
+			return withLineNumbers(highlightJSON(buf.String()))
+		}
+	}
+	return withLineNumbers(strings.ReplaceAll(body, "\t", "    "))
+}
+
+// isJSON reports whether the body should be treated as JSON: either the server says so,
+// or it looks like an object/array and parses as one.
+func isJSON(body string, headers http.Header) bool {
+	if strings.Contains(strings.ToLower(headers.Get("Content-Type")), "json") {
+		return json.Valid([]byte(body))
+	}
+	trimmed := strings.TrimSpace(body)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// withLineNumbers prefixes each line with a right-aligned line number.
+func withLineNumbers(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	width := len(fmt.Sprint(len(lines)))
+
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(lineNumberStyle.Render(fmt.Sprintf("%*d ", width, i+1)))
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// highlightJSON colours indented JSON. It relies on json.Indent output, where no token
+// spans lines, and only needs to tell keys from string values.
+func highlightJSON(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			end := stringEnd(s, i)
+			token := s[i:end]
+			// A string followed by ':' is an object key
+			if end < len(s) && s[end] == ':' {
+				sb.WriteString(jsonKeyStyle.Render(token))
+			} else {
+				sb.WriteString(jsonStringStyle.Render(token))
+			}
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(s) && strings.IndexByte("0123456789.eE+-", s[end]) >= 0 {
+				end++
+			}
+			sb.WriteString(jsonNumberStyle.Render(s[i:end]))
+			i = end
+		case c == 't' || c == 'f' || c == 'n':
+			end := i + 1
+			for end < len(s) && s[end] >= 'a' && s[end] <= 'z' {
+				end++
+			}
+			sb.WriteString(jsonLiteralStyle.Render(s[i:end]))
+			i = end
+		case strings.IndexByte("{}[],:", c) >= 0:
+			sb.WriteString(jsonPunctStyle.Render(string(c)))
+			i++
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// stringEnd returns the index just past the JSON string starting at s[start].
+func stringEnd(s string, start int) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -1,9 +1,17 @@
 package tui
 
 import (
+	"lazycurl/internal/history"
 	"lazycurl/internal/load"
+	"lazycurl/internal/model"
+	"lazycurl/internal/postman"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
 )
 
 // WaitForStats produces a command that waits for a message on the channel.
@@ -15,3 +23,52 @@ func WaitForStats(ch chan load.StatsMsg) tea.Cmd {
 		return nil
 	}
 }
+
+// requestDoneMsg carries the result of RunRequestCmd and its history entry.
+type requestDoneMsg struct {
+	Response   model.Response
+	Entry      history.Entry
+	HistoryErr error
+}
+
+// importDoneMsg carries the result of ImportPostmanCmd.
+type importDoneMsg struct {
+	Result *postman.Result
+	Err    error
+}
+
+// ImportPostmanCmd reads a Postman collection file in the background.
+func ImportPostmanCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		path = strings.TrimSpace(path)
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		result, err := postman.ImportFiles(path, nil)
+		return importDoneMsg{Result: result, Err: err}
+	}
+}
+
+// clipboardHold is how long a copy sequence stays in the view, enough for the
+// renderer to draw at least one frame with it.
+const clipboardHold = 200 * time.Millisecond
+
+// clipboardClearMsg drops the copy sequence Seq from the view once it has been drawn.
+type clipboardClearMsg struct {
+	Seq string
+}
+
+// copyToClipboard copies text to the system clipboard with an OSC 52 escape sequence,
+// which also works over SSH in terminals that support it. The sequence goes out with
+// the next frames, as writing it to stdout directly would race the renderer; the
+// returned command removes it again.
+func (m *Model) copyToClipboard(text string) tea.Cmd {
+	var seq strings.Builder
+	termenv.NewOutput(&seq).Copy(text)
+	m.Clipboard = seq.String()
+	return tea.Tick(clipboardHold, func(time.Time) tea.Msg {
+		return clipboardClearMsg{Seq: seq.String()}
+	})
+}
@@ -9,6 +9,8 @@ type KeyMap struct {
 	Tab      key.Binding
 	ShiftTab key.Binding
 	Run      key.Binding
+	Cancel   key.Binding
+	Env      key.Binding // Cycle the active environment
 	Help     key.Binding
 
 	// Requests Pane
@@ -16,10 +18,30 @@ type KeyMap struct {
 	Down   key.Binding
 	New    key.Binding
 	Delete key.Binding
+	Import key.Binding
+
+	// Requests Pane: sections, History and Runs
+	Section key.Binding // Cycle Requests / History / Runs
+	Filter  key.Binding
+	Restore key.Binding
+	Compare key.Binding // Runs: mark the selected run to compare others against
 
 	// Editor Pane
 	EditEnter key.Binding // Enter edit mode
 	EditEsc   key.Binding // Exit edit mode
+
+	// Response Pane
+	SwitchView key.Binding // Cycle Body / Headers / Timing
+	RawBody    key.Binding // Toggle raw / pretty body
+	Scroll     key.Binding // Help only; scrolling uses the viewport's own keys
+	Tree       key.Binding // Toggle the JSON tree explorer
+	Collapse   key.Binding // Tree: fold node / go to parent
+	Expand     key.Binding // Tree: unfold node
+	CopyPath   key.Binding // Tree: copy the path of the selected node
+	CopyValue  key.Binding // Tree: copy the value of the selected node
+	BodyFilter key.Binding // Filter the body with a jq expression
+	NextGraph  key.Binding // Load dashboard: show the next per-second graph
+	Export     key.Binding // Load dashboard: write the results to files
 }
 
 // DefaultKeyMap returns the default keybindings.
@@ -41,6 +63,14 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "run request"),
 		),
+		Cancel: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "cancel"),
+		),
+		Env: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "switch env"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -61,6 +91,26 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("d", "delete"), // 'd' might conflict if we allow typing, but in nav mode it is fine
 			key.WithHelp("d", "delete"),
 		),
+		Import: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "import postman"),
+		),
+		Section: key.NewBinding(
+			key.WithKeys("left", "h", "right", "l"),
+			key.WithHelp("h/l", "requests/history/runs"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter history"),
+		),
+		Restore: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "restore as new request"),
+		),
+		Compare: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "compare runs"),
+		),
 		EditEnter: key.NewBinding(
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "edit field"),
@@ -69,18 +119,65 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "exit edit"),
 		),
+		SwitchView: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "switch view"),
+		),
+		RawBody: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "raw/pretty"),
+		),
+		Scroll: key.NewBinding(
+			key.WithKeys("j", "k", "pgup", "pgdown"),
+			key.WithHelp("j/k/pgdn/←/→", "scroll"),
+		),
+		Tree: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "json tree"),
+		),
+		Collapse: key.NewBinding(
+			key.WithKeys("h", "left"),
+			key.WithHelp("h", "fold"),
+		),
+		Expand: key.NewBinding(
+			key.WithKeys("l", "right"),
+			key.WithHelp("l", "unfold"),
+		),
+		CopyPath: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy path"),
+		),
+		CopyValue: key.NewBinding(
+			key.WithKeys("Y"),
+			key.WithHelp("Y", "copy value"),
+		),
+		BodyFilter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "jq filter"),
+		),
+		NextGraph: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "next load graph"),
+		),
+		Export: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "export load results"),
+		),
 	}
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view.
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Help, k.Quit, k.Tab, k.Run}
+	return []key.Binding{k.Help, k.Quit, k.Tab, k.Run, k.Cancel, k.Env}
 }
 
 // FullHelp returns keybindings for the expanded help view.
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.New, k.Delete},    // Requests
-		{k.Tab, k.ShiftTab, k.Run, k.Quit}, // Global
+		{k.Up, k.Down, k.New, k.Delete, k.Import},   // Requests
+		{k.Section, k.Filter, k.Restore, k.Compare}, // History and Runs
+		{k.SwitchView, k.RawBody, k.Scroll, k.BodyFilter, k.NextGraph, k.Export},
+		{k.Tree, k.Collapse, k.Expand, k.CopyPath, k.CopyValue}, // Response
+		{k.Tab, k.ShiftTab, k.Run, k.Cancel, k.Env, k.Quit},     // Global
 	}
 }
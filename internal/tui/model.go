@@ -1,13 +1,26 @@
 package tui
 
 import (
-	"lazycurl/internal/curl"
+	"context"
+	"lazycurl/internal/env"
+	"lazycurl/internal/history"
+	"lazycurl/internal/jq"
 	"lazycurl/internal/load"
 	"lazycurl/internal/model"
+	"lazycurl/internal/runs"
+	"lazycurl/internal/transport"
+	"lazycurl/internal/workspace"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -20,11 +33,24 @@ const (
 	PaneResponse
 )
 
+// LeftSection represents the list shown in the left pane.
+type LeftSection int
+
+const (
+	SectionRequests LeftSection = iota
+	SectionHistory
+	SectionRuns
+)
+
+// historyLimit is how many recent history entries are kept in the TUI.
+const historyLimit = 500
+
 // EditorField represents a field in the editor pane.
 type EditorField int
 
 const (
-	FieldMethod EditorField = iota
+	FieldName EditorField = iota
+	FieldMethod
 	FieldURL
 	FieldTabs    // Focus on the tab bar
 	FieldContent // Focus on the content (Body or Headers list)
@@ -36,7 +62,18 @@ type EditorTab int
 const (
 	TabHeaders EditorTab = iota
 	TabBody
+	TabSettings
 	TabLoad
+	tabCount
+)
+
+// ResponseView represents the active sub-view of the response pane.
+type ResponseView int
+
+const (
+	ResponseBody ResponseView = iota
+	ResponseHeaders
+	ResponseTiming
 )
 
 // InputPair represents a key-value input pair (for headers).
@@ -45,15 +82,45 @@ type InputPair struct {
 	Value textinput.Model
 }
 
+// RequestSettings holds the per-request options edited in the Settings tab.
+type RequestSettings struct {
+	Backend        textinput.Model
+	Timeout        textinput.Model
+	ConnectTimeout textinput.Model
+}
+
 type LoadConfig struct {
 	Concurrency textinput.Model
 	Duration    textinput.Model
+	RPS         textinput.Model // Target arrival rate; empty runs a closed loop
+	Stages      textinput.Model // Load profile, e.g. "30s:50, 1m:50, 10s:0"; replaces the above
+	Backend     textinput.Model // Overrides the request's backend for the whole test
+	Thresholds  textinput.Model // Comma-separated, e.g. "p95<300ms, error_rate<1%"
+	AbortOnFail textinput.Model // "y" stops the test once a threshold can no longer pass
+	DataFile    textinput.Model // CSV or JSONL rows filling {{variables}} per iteration
+	DataMode    textinput.Model // Strategy and end of data, e.g. "unique, stop"
+	Scenario    textinput.Model // Saved requests sent instead of the selected one, e.g. "List=70, Get=30"
+}
+
+// formField is a labelled input of a form-style editor tab (Settings, Load).
+type formField struct {
+	Label string
+	Input *textinput.Model
 }
 
 type LoadState struct {
 	IsRunning bool
 	Stats     *load.Stats
 	Sub       chan load.StatsMsg // Active subscription
+	Cancel    context.CancelFunc // Aborts the running test
+	Graph     int                // Index into dashboardGraphs
+	Samples   *load.SampleLog    // Every request of the test, for export
+
+	// What the test was started with, to save it as a run when it ends
+	Started  time.Time
+	Request  model.Request
+	Scenario []load.ScenarioStep
+	Config   runs.Config
 }
 
 // Model represents the state of the TUI.
@@ -64,33 +131,79 @@ type Model struct {
 	Help       help.Model
 	Width      int
 	Height     int
-	Executor   *curl.Executor
+	Executor   transport.Executor
+	Workspace  *workspace.Workspace // Nil keeps requests in memory only
+	Envs       *env.Set             // Environments; the active one resolves {{var}} at run time
+	Status     string               // Last status/error message for the bottom bar
 
 	// Requests Pane State
+	ActiveSection  LeftSection
 	Requests       []model.Request
 	SelectedReqIdx int
 
+	// Import State
+	IsImporting bool // Typing the path of a Postman collection
+	ImportInput textinput.Model
+
+	// History State
+	History         *history.Store  // Nil when running without a workspace
+	HistoryEntries  []history.Entry // Newest first
+	HistoryFilter   textinput.Model
+	SelectedHistIdx int // Index into the filtered entries
+
+	// Runs State
+	Runs           *runs.Store // Nil when running without a workspace
+	RunEntries     []runs.Run  // Newest first
+	SelectedRunIdx int
+	CompareRunID   string // Run marked to compare the selected one against
+	ShowRun        bool   // The response pane shows the selected run instead of the last response
+
 	// Editor Pane State
 	ActiveEditorTab  EditorTab
-	EditorInputs     []textinput.Model // Method, URL
+	EditorInputs     []textinput.Model // Method, URL, Name
 	HeaderInputs     []InputPair       // Headers
 	EditorBody       textarea.Model    // Body
+	BodyMode         model.BodyMode    // How the body is sent
+	BodyContentType  textinput.Model   // Content type of a raw or binary body
+	BodyFile         textinput.Model   // File sent as a binary body
+	FormInputs       []InputPair       // Fields of a urlencoded or multipart body
+	Settings         RequestSettings   // Settings
 	LoadConfig       LoadConfig        // Load Config
 	FocusedField     EditorField
 	FocusedHeaderIdx int  // Index of the header being edited
 	FocusedHeaderKey bool // True if editing Key, False if Value
 	IsEditing        bool // True if user is typing in a field
 
+	// Run State
+	IsRunningRequest bool
+	CancelRequest    context.CancelFunc // Aborts the in-flight request
+	Spinner          spinner.Model
+	Quitting         bool // Quit asked for while something ran; quits once it has stopped
+
 	// Load Test State
 	LoadState LoadState
 
 	// Response Pane State
-	Response *model.Response
+	Response           *model.Response
+	ActiveResponseView ResponseView
+	ResponseViewport   viewport.Model // Scrollable content of the active view
+	RawBody            bool           // Show the body as received instead of pretty-printed
+	TreeMode           bool           // Explore a JSON body as a collapsible tree
+	Tree               *jsonTree      // Built from the body on first use; nil if it isn't JSON
+	ResponseFilter     textinput.Model
+	IsFilteringBody    bool   // Typing in the response filter
+	FilterOutput       string // Body passed through the selected request's filter
+	FilterErr          error
+	Clipboard          string // OSC 52 sequence of a copy, drawn with the next frames
 }
 
-// NewModel creates the initial model.
-func NewModel() Model {
+// NewModel creates the initial model, loading the request collection from ws.
+func NewModel(ws *workspace.Workspace) (Model, error) {
 	// Initialize inputs
+	nameInput := textinput.New()
+	nameInput.Placeholder = "Untitled"
+	nameInput.Width = 50
+
 	methodInput := textinput.New()
 	methodInput.Placeholder = "GET"
 	methodInput.CharLimit = 10
@@ -104,6 +217,14 @@ func NewModel() Model {
 	bodyInput.SetHeight(10)
 	bodyInput.ShowLineNumbers = false
 
+	contentTypeInput := textinput.New()
+	contentTypeInput.Placeholder = "application/x-www-form-urlencoded"
+	contentTypeInput.Width = 50
+
+	bodyFileInput := textinput.New()
+	bodyFileInput.Placeholder = "path/to/file"
+	bodyFileInput.Width = 50
+
 	// Load Inputs
 	concInput := textinput.New()
 	concInput.Placeholder = "10"
@@ -115,29 +236,145 @@ func NewModel() Model {
 	durInput.SetValue("5s")
 	durInput.CharLimit = 5
 
-	// Initial default request
-	defaultReq := model.NewRequest()
+	rpsInput := textinput.New()
+	rpsInput.Placeholder = "closed loop"
+	rpsInput.CharLimit = 8
+
+	stagesInput := textinput.New()
+	stagesInput.Placeholder = "30s:50, 1m:50, 10s:0"
+
+	loadBackendInput := textinput.New()
+	loadBackendInput.Placeholder = "per request"
+	loadBackendInput.CharLimit = 10
+
+	thresholdsInput := textinput.New()
+	thresholdsInput.Placeholder = "p95<300ms, error_rate<1%"
+
+	abortOnFailInput := textinput.New()
+	abortOnFailInput.Placeholder = "n"
+	abortOnFailInput.CharLimit = 3
+
+	dataFileInput := textinput.New()
+	dataFileInput.Placeholder = "none"
+
+	dataModeInput := textinput.New()
+	dataModeInput.Placeholder = "sequential, recycle"
+
+	scenarioInput := textinput.New()
+	scenarioInput.Placeholder = "selected request"
+
+	// Settings Inputs
+	backendInput := textinput.New()
+	backendInput.Placeholder = transport.Curl
+	backendInput.CharLimit = 10
+
+	timeoutInput := textinput.New()
+	timeoutInput.Placeholder = "none"
+	timeoutInput.CharLimit = 10
+
+	connectTimeoutInput := textinput.New()
+	connectTimeoutInput.Placeholder = "default"
+	connectTimeoutInput.CharLimit = 10
+
+	historyFilter := textinput.New()
+	historyFilter.Placeholder = "filter (method, url, status)"
+	historyFilter.Prompt = "/ "
+
+	responseFilter := textinput.New()
+	responseFilter.Placeholder = "jq filter, e.g. .items[0].id"
+	responseFilter.Prompt = "jq "
+
+	importInput := textinput.New()
+	importInput.Placeholder = "path/to/collection.json"
+	importInput.Prompt = "Import Postman: "
+
+	// Long lines (e.g. raw minified JSON) scroll sideways with the arrow keys; h/l stay free
+	responseViewport := viewport.New(0, 0)
+	responseViewport.SetHorizontalStep(8)
+	responseViewport.KeyMap.Left.SetKeys("left")
+	responseViewport.KeyMap.Right.SetKeys("right")
+
+	spin := spinner.New()
+	spin.Spinner = spinner.Dot
+
+	var requests []model.Request
+	if ws != nil {
+		var err error
+		if requests, err = ws.LoadRequests(); err != nil {
+			return Model{}, err
+		}
+	}
+	envs := &env.Set{}
+	if ws != nil {
+		var err error
+		if envs, err = ws.LoadEnvironments(); err != nil {
+			return Model{}, err
+		}
+	}
+
+	var historyStore *history.Store
+	var historyEntries []history.Entry
+	status := ""
+	if ws != nil {
+		historyStore = history.NewStore(ws.Path(history.File))
+		var err error
+		if historyEntries, err = historyStore.Load(historyLimit); err != nil {
+			status = err.Error() // History is a convenience; don't refuse to start over it
+		}
+	}
+	var runStore *runs.Store
+	var runEntries []runs.Run
+	if ws != nil {
+		runStore = runs.NewStore(ws.Path(runs.Dir))
+		var err error
+		if runEntries, err = runStore.Load(); err != nil {
+			status = err.Error()
+		}
+	}
+	if len(requests) == 0 {
+		// Initial default request
+		requests = []model.Request{model.NewRequest()}
+	}
 
 	m := Model{
-		ActivePane:       PaneRequests,
-		KeyMap:           DefaultKeyMap(),
-		Help:             help.New(),
-		Executor:         curl.NewExecutor(),
-		Requests:         []model.Request{defaultReq},
-		SelectedReqIdx:   0,
-		ActiveEditorTab:  TabBody, // Default to Body
-		EditorInputs:     []textinput.Model{methodInput, urlInput},
-		EditorBody:       bodyInput,
-		LoadConfig:       LoadConfig{Concurrency: concInput, Duration: durInput},
+		ActivePane:      PaneRequests,
+		KeyMap:          DefaultKeyMap(),
+		Help:            help.New(),
+		Executor:        transport.NewMux(transport.Curl),
+		Workspace:       ws,
+		Envs:            envs,
+		Status:          status,
+		History:         historyStore,
+		HistoryEntries:  historyEntries,
+		HistoryFilter:   historyFilter,
+		Runs:            runStore,
+		RunEntries:      runEntries,
+		ImportInput:     importInput,
+		Requests:        requests,
+		SelectedReqIdx:  0,
+		ActiveEditorTab: TabBody, // Default to Body
+		EditorInputs:    []textinput.Model{methodInput, urlInput, nameInput},
+		EditorBody:      bodyInput,
+		BodyContentType: contentTypeInput,
+		BodyFile:        bodyFileInput,
+		Settings:        RequestSettings{Backend: backendInput, Timeout: timeoutInput, ConnectTimeout: connectTimeoutInput},
+		Spinner:         spin,
+		LoadConfig: LoadConfig{
+			Concurrency: concInput, Duration: durInput, RPS: rpsInput, Stages: stagesInput, Backend: loadBackendInput,
+			Thresholds: thresholdsInput, AbortOnFail: abortOnFailInput, DataFile: dataFileInput, DataMode: dataModeInput,
+			Scenario: scenarioInput,
+		},
 		FocusedField:     FieldMethod,
 		IsEditing:        false,
 		FocusedHeaderIdx: 0,
 		HeaderInputs:     []InputPair{},
 		LoadState:        LoadState{IsRunning: false},
+		ResponseViewport: responseViewport,
+		ResponseFilter:   responseFilter,
 	}
 
 	m.SyncEditorToRequest()
-	return m
+	return m, nil
 }
 
 // SyncEditorToRequest populates editor fields from the currently selected request.
@@ -148,7 +385,17 @@ func (m *Model) SyncEditorToRequest() {
 	req := m.Requests[m.SelectedReqIdx]
 	m.EditorInputs[0].SetValue(req.Method)
 	m.EditorInputs[1].SetValue(req.URL)
+	m.EditorInputs[2].SetValue(req.Name)
 	m.EditorBody.SetValue(req.Body)
+	m.BodyMode = req.BodyMode
+	m.BodyContentType.SetValue(req.ContentType)
+	m.BodyFile.SetValue(req.BodyFile)
+	m.Settings.Backend.SetValue(req.Backend)
+	m.Settings.Timeout.SetValue(formatDuration(req.Timeout))
+	m.Settings.ConnectTimeout.SetValue(formatDuration(req.ConnectTimeout))
+	m.ResponseFilter.SetValue(req.Filter)
+	m.applyResponseFilter()
+	m.refreshResponseView()
 
 	// Sync Headers
 	m.HeaderInputs = []InputPair{}
@@ -171,6 +418,43 @@ func (m *Model) SyncEditorToRequest() {
 		vInput.Placeholder = "Value"
 		m.HeaderInputs = append(m.HeaderInputs, InputPair{Key: kInput, Value: vInput})
 	}
+
+	// Sync Form Fields; in a multipart body a value of @path attaches a file
+	m.FormInputs = []InputPair{}
+	for _, f := range req.Form {
+		pair := newFormInput()
+		pair.Key.SetValue(f.Name)
+		if f.File {
+			pair.Value.SetValue("@" + f.Value)
+		} else {
+			pair.Value.SetValue(f.Value)
+		}
+		m.FormInputs = append(m.FormInputs, pair)
+	}
+	if len(m.FormInputs) == 0 {
+		m.FormInputs = append(m.FormInputs, newFormInput())
+	}
+}
+
+// newFormInput returns an empty row for the form of a body.
+func newFormInput() InputPair {
+	kInput := textinput.New()
+	kInput.Placeholder = "Field"
+	vInput := textinput.New()
+	vInput.Placeholder = "Value"
+	return InputPair{Key: kInput, Value: vInput}
+}
+
+// newPair returns an empty row for the key/value list of the active tab.
+func (m *Model) newPair() InputPair {
+	if m.ActiveEditorTab == TabBody {
+		return newFormInput()
+	}
+	kInput := textinput.New()
+	kInput.Placeholder = "Header"
+	vInput := textinput.New()
+	vInput.Placeholder = "Value"
+	return InputPair{Key: kInput, Value: vInput}
 }
 
 // SyncRequestToEditor updates the selected request from editor fields.
@@ -181,7 +465,15 @@ func (m *Model) SyncRequestToEditor() {
 	req := &m.Requests[m.SelectedReqIdx]
 	req.Method = m.EditorInputs[0].Value()
 	req.URL = m.EditorInputs[1].Value()
+	req.Name = strings.TrimSpace(m.EditorInputs[2].Value())
 	req.Body = m.EditorBody.Value()
+	req.BodyMode = m.BodyMode
+	req.ContentType = strings.TrimSpace(m.BodyContentType.Value())
+	req.BodyFile = strings.TrimSpace(m.BodyFile.Value())
+	req.Backend = strings.TrimSpace(m.Settings.Backend.Value())
+	req.Timeout = parseDuration(m.Settings.Timeout.Value())
+	req.ConnectTimeout = parseDuration(m.Settings.ConnectTimeout.Value())
+	req.Filter = strings.TrimSpace(m.ResponseFilter.Value())
 
 	// Sync Headers
 	req.Headers = make(map[string]string)
@@ -192,9 +484,287 @@ func (m *Model) SyncRequestToEditor() {
 			req.Headers[k] = v
 		}
 	}
+
+	// Sync Form Fields
+	req.Form = nil
+	for _, pair := range m.FormInputs {
+		f := model.FormField{Name: pair.Key.Value(), Value: pair.Value.Value()}
+		if f.Name == "" {
+			continue
+		}
+		if path, ok := strings.CutPrefix(f.Value, "@"); ok && m.BodyMode == model.BodyMultipart {
+			f.Value, f.File = path, true
+		}
+		req.Form = append(req.Form, f)
+	}
+}
+
+// SaveRequests writes the request collection to the workspace, if any.
+// Failures are reported in the status bar rather than interrupting the user.
+func (m *Model) SaveRequests() error {
+	if m.Workspace == nil {
+		return nil
+	}
+	err := m.Workspace.SaveRequests(m.Requests)
+	if err != nil {
+		m.Status = "Save failed: " + err.Error()
+	}
+	return err
+}
+
+// SaveEnvironments writes the environments to the workspace, if any.
+func (m *Model) SaveEnvironments() error {
+	if m.Workspace == nil {
+		return nil
+	}
+	err := m.Workspace.SaveEnvironments(m.Envs)
+	if err != nil {
+		m.Status = "Save failed: " + err.Error()
+	}
+	return err
+}
+
+// saveRun records the load test that just ended with stats s as a run, saving it
+// to the workspace if any. Tests stopped before anything completed are left out.
+func (m *Model) saveRun(s *load.Stats) {
+	if s.TotalRequests == 0 && s.CutOff == 0 {
+		return
+	}
+	run := runs.NewRun(m.LoadState.Started, m.LoadState.Request, m.Envs.Active,
+		maps.Clone(m.Envs.ActiveVars()), m.LoadState.Config, s)
+	run.Scenario = m.LoadState.Scenario
+	m.RunEntries = append([]runs.Run{run}, m.RunEntries...)
+	m.SelectedRunIdx = 0
+	if m.Runs != nil {
+		if err := m.Runs.Save(run); err != nil {
+			m.Status = err.Error()
+		}
+	}
+}
+
+// quitWhenStopped quits if a quit was put off until the running request and load
+// test had stopped and they now have.
+func (m Model) quitWhenStopped() tea.Cmd {
+	if m.Quitting && !m.IsRunningRequest && !m.LoadState.IsRunning {
+		return tea.Quit
+	}
+	return nil
+}
+
+// exportLoad writes the results shown, of the load test or the saved run, to a new
+// directory under exports/ in the workspace: the per-second windows as CSV and a
+// Prometheus snapshot, plus every request as CSV and JSON lines for a test.
+func (m *Model) exportLoad() {
+	var report load.Report
+	var started time.Time
+	samples := m.LoadState.Samples
+	if m.ShowRun {
+		run, ok := m.selectedRun()
+		if !ok {
+			return
+		}
+		report, started, samples = run.Report, run.Time, nil // Runs keep no samples
+	} else {
+		report, started = load.NewReport(m.LoadState.Stats), m.LoadState.Started
+	}
+
+	dir := filepath.Join("exports", "load-"+started.Format("20060102-150405"))
+	if m.Workspace != nil {
+		dir = m.Workspace.Path(dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		m.Status = "Export failed: " + err.Error()
+		return
+	}
+	type export struct {
+		name  string
+		write func(path string) error
+	}
+	exports := []export{
+		{"per_second.csv", func(path string) error { return load.WritePerSecondFile(path, report) }},
+		{"metrics.prom", func(path string) error { return load.WritePrometheusFile(path, report) }},
+	}
+	if samples != nil {
+		exports = append(exports, export{"samples.csv", samples.WriteFile}, export{"samples.jsonl", samples.WriteFile})
+	}
+	for _, e := range exports {
+		if err := e.write(filepath.Join(dir, e.name)); err != nil {
+			m.Status = "Export failed: " + err.Error()
+			return
+		}
+	}
+	m.Status = "Exported to " + dir
+}
+
+// resolvedRequest returns the selected request with the active environment applied,
+// warning in the status bar about variables the environment doesn't define.
+// References to fed are left for a load test's data file.
+func (m *Model) resolvedRequest(fed ...string) model.Request {
+	req, missing := env.ResolveExcept(m.Requests[m.SelectedReqIdx], m.Envs.ActiveVars(), fed)
+	if len(missing) > 0 {
+		m.Status = "Warning: undefined variables: " + strings.Join(missing, ", ")
+	} else {
+		m.Status = ""
+	}
+	return req
+}
+
+// filteredHistory returns the history entries matching the filter, newest first.
+func (m *Model) filteredHistory() []history.Entry {
+	query := m.HistoryFilter.Value()
+	if query == "" {
+		return m.HistoryEntries
+	}
+	var entries []history.Entry
+	for _, e := range m.HistoryEntries {
+		if e.Matches(query) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// selectedHistoryEntry returns the entry under the cursor in the History section.
+func (m *Model) selectedHistoryEntry() (history.Entry, bool) {
+	entries := m.filteredHistory()
+	if m.SelectedHistIdx < 0 || m.SelectedHistIdx >= len(entries) {
+		return history.Entry{}, false
+	}
+	return entries[m.SelectedHistIdx], true
+}
+
+// selectedRun returns the run under the cursor in the Runs section.
+func (m *Model) selectedRun() (runs.Run, bool) {
+	if m.SelectedRunIdx < 0 || m.SelectedRunIdx >= len(m.RunEntries) {
+		return runs.Run{}, false
+	}
+	return m.RunEntries[m.SelectedRunIdx], true
+}
+
+// compareRun returns the run marked for comparison.
+func (m *Model) compareRun() (runs.Run, bool) {
+	for _, r := range m.RunEntries {
+		if r.ID == m.CompareRunID {
+			return r, true
+		}
+	}
+	return runs.Run{}, false
+}
+
+// formFields returns the inputs of the active editor tab when it is a form, in display order.
+func (m *Model) formFields() []formField {
+	switch m.ActiveEditorTab {
+	case TabSettings:
+		return []formField{
+			{Label: "Backend (curl/native)", Input: &m.Settings.Backend},
+			{Label: "Timeout (e.g. 30s)", Input: &m.Settings.Timeout},
+			{Label: "Connect Timeout", Input: &m.Settings.ConnectTimeout},
+		}
+	case TabLoad:
+		return []formField{
+			{Label: "Concurrency (max in flight with RPS)", Input: &m.LoadConfig.Concurrency},
+			{Label: "Duration", Input: &m.LoadConfig.Duration},
+			{Label: "Target RPS (empty: closed loop)", Input: &m.LoadConfig.RPS},
+			{Label: "Stages (workers, or e.g. 30s:100rps)", Input: &m.LoadConfig.Stages},
+			{Label: "Backend (empty: per request)", Input: &m.LoadConfig.Backend},
+			{Label: "Thresholds (e.g. p95<300ms, rps>100)", Input: &m.LoadConfig.Thresholds},
+			{Label: "Abort on Failed Threshold (y/n)", Input: &m.LoadConfig.AbortOnFail},
+			{Label: "Data File (CSV/JSONL rows fill {{vars}})", Input: &m.LoadConfig.DataFile},
+			{Label: "Data Strategy, End (e.g. unique, stop)", Input: &m.LoadConfig.DataMode},
+			{Label: "Scenario (e.g. List=70, Get=30 or A -> B=1s)", Input: &m.LoadConfig.Scenario},
+		}
+	}
+	return nil
+}
+
+// bodyRows returns how many rows the cursor moves over in the Body tab.
+func (m *Model) bodyRows() int {
+	switch m.BodyMode {
+	case model.BodyJSON:
+		return 1 // Text
+	case model.BodyURLEncoded, model.BodyMultipart:
+		return len(m.FormInputs)
+	default:
+		return 2 // Content Type and Text, or File and Content Type
+	}
+}
+
+// focusedBodyInput returns the Body tab input under the cursor, or nil for the body text.
+func (m *Model) focusedBodyInput() *textinput.Model {
+	idx := m.FocusedHeaderIdx
+	switch m.BodyMode {
+	case model.BodyURLEncoded, model.BodyMultipart:
+		if idx >= len(m.FormInputs) {
+			return nil
+		}
+		if m.FocusedHeaderKey {
+			return &m.FormInputs[idx].Key
+		}
+		return &m.FormInputs[idx].Value
+	case model.BodyBinary:
+		if idx == 0 {
+			return &m.BodyFile
+		}
+		return &m.BodyContentType
+	case model.BodyRaw:
+		if idx == 0 {
+			return &m.BodyContentType
+		}
+	}
+	return nil
+}
+
+// editedPairs returns the key/value rows of the active tab, or nil if it has none.
+func (m *Model) editedPairs() *[]InputPair {
+	if m.ActiveEditorTab == TabHeaders {
+		return &m.HeaderInputs
+	}
+	if m.ActiveEditorTab == TabBody && (m.BodyMode == model.BodyURLEncoded || m.BodyMode == model.BodyMultipart) {
+		return &m.FormInputs
+	}
+	return nil
+}
+
+// focusedFormField returns the form input under the cursor, or nil if the active tab is not a form.
+func (m *Model) focusedFormField() *textinput.Model {
+	fields := m.formFields()
+	if len(fields) == 0 {
+		return nil
+	}
+	idx := m.FocusedHeaderIdx
+	if idx >= len(fields) {
+		idx = len(fields) - 1
+	}
+	return fields[idx].Input
+}
+
+// formatDuration renders an optional duration for an input; zero stays empty.
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// parseDuration reads an optional duration input; empty or invalid means zero.
+func parseDuration(v string) time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(v))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
 }
 
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
 	return nil
 }
+
+// applyResponseFilter runs the response body through the filter being shown.
+func (m *Model) applyResponseFilter() {
+	m.FilterOutput, m.FilterErr = "", nil
+	if m.Response == nil || strings.TrimSpace(m.ResponseFilter.Value()) == "" {
+		return
+	}
+	m.FilterOutput, m.FilterErr = jq.Apply(context.Background(), m.Response.Body, m.ResponseFilter.Value())
+}
@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"lazycurl/internal/load"
+	"lazycurl/internal/runs"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// runTimeFormat labels a run in the Runs list and the run views.
+const runTimeFormat = "Jan 2 15:04:05"
+
+func (m Model) viewRuns(width, height int) string {
+	if len(m.RunEntries) == 0 {
+		return "No runs yet. Run a load test from the Load tab."
+	}
+
+	// Only render the window of runs around the cursor
+	start, end := visibleRange(m.SelectedRunIdx, len(m.RunEntries), height)
+
+	var items []string
+	for i := start; i < end; i++ {
+		r := m.RunEntries[i]
+		mark := " "
+		if r.ID == m.CompareRunID {
+			mark = "*"
+		}
+		line := truncate(fmt.Sprintf("%s%s %s %.1frps p95 %s", mark, r.Time.Format("Jan 2 15:04"), r.Title(),
+			r.Report.RPS, formatMillis(r.Report.LatencyMS.P95)), width-4)
+
+		if i == m.SelectedRunIdx {
+			items = append(items, selectedItemStyle.Render(">"+line))
+		} else {
+			items = append(items, itemStyle.Render(" "+line))
+		}
+	}
+	return strings.Join(items, "\n")
+}
+
+// viewSelectedRun shows the selected run, or compares it with the marked one.
+func (m Model) viewSelectedRun(width, height int) string {
+	run, ok := m.selectedRun()
+	if !ok {
+		return "No runs yet."
+	}
+	if base, ok := m.compareRun(); ok && base.ID != run.ID {
+		return viewRunCompare(base, run, width)
+	}
+	return m.viewRun(run, width, height)
+}
+
+// viewRun shows the results of a saved run.
+func (m Model) viewRun(r runs.Run, width, height int) string {
+	rep := r.Report
+	state := "Done"
+	if rep.AbortedBy != "" {
+		state = "Aborted: " + rep.AbortedBy + " failed"
+	} else if rep.Aborted {
+		state = "Aborted"
+	} else if rep.DataExhausted {
+		state = "Done: data file ran out"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Run %s: %s (c: compare, d: delete)\n", r.Time.Format(runTimeFormat), state))
+	target := r.Request.Method + " " + r.Request.URL
+	if len(r.Scenario) > 0 {
+		target = r.Title()
+	}
+	sb.WriteString(truncate(target, width-2) + "\n")
+	config := r.Config.Describe()
+	if r.Env != "" {
+		config += ", env " + r.Env
+	}
+	sb.WriteString(truncate(config, width-2) + "\n\n")
+
+	sb.WriteString(fmt.Sprintf("Requests: %d in %s (%.1f rps), %d cut off\n",
+		rep.Requests, time.Duration(rep.DurationS*float64(time.Second)).Round(time.Millisecond), rep.RPS, rep.CutOff))
+	if rep.TargetRPS > 0 {
+		sb.WriteString(fmt.Sprintf("Target: %g rps, %d dropped, %d late\n", rep.TargetRPS, rep.Dropped, rep.Late))
+	}
+	l := rep.LatencyMS
+	sb.WriteString(fmt.Sprintf("Latency: p50 %s  p90 %s  p95 %s\n", formatMillis(l.P50), formatMillis(l.P90), formatMillis(l.P95)))
+	sb.WriteString(fmt.Sprintf("         p99 %s  p99.9 %s  max %s\n", formatMillis(l.P99), formatMillis(l.P999), formatMillis(l.Max)))
+	sb.WriteString(fmt.Sprintf("         min %s  mean %s  stddev %s\n", formatMillis(l.Min), formatMillis(l.Mean), formatMillis(l.StdDev)))
+
+	sb.WriteString("Status codes: " + formatCounts(rep.StatusCodes) + "\n")
+
+	graphHeight := max(height/3, 5)
+	sb.WriteString("\n" + m.viewGraphTabs() + "\n")
+	sb.WriteString(m.viewGraph(reportWindows(rep), max(width-12, 10), graphHeight) + "\n")
+
+	if rep.Failures > 0 {
+		sb.WriteString(fmt.Sprintf("\nFailures: %d (%.1f%%)", rep.Failures, 100*float64(rep.Failures)/float64(max(rep.Requests, 1))))
+	}
+	for _, class := range load.ErrorClasses {
+		if c, ok := rep.ErrorClasses[class]; ok {
+			line := fmt.Sprintf("  %s: %d, first at %.1fs: %s", class, c.Count, c.FirstSeenS, c.Sample)
+			sb.WriteString("\n" + thresholdFailStyle.Render(truncate(line, width-2)))
+		}
+	}
+	if len(rep.PerRequest) > 0 {
+		sb.WriteString("\nRequests:")
+	}
+	for _, st := range rep.PerRequest {
+		line := fmt.Sprintf("  %s: %d reqs, %d failures, %s", st.Name, st.Requests, st.Failures, formatCounts(st.StatusCodes))
+		sb.WriteString("\n" + truncate(line, width-2))
+		sb.WriteString(fmt.Sprintf("\n    p50 %s  p95 %s  p99 %s",
+			formatMillis(st.LatencyMS.P50), formatMillis(st.LatencyMS.P95), formatMillis(st.LatencyMS.P99)))
+	}
+	if len(rep.Stages) > 0 {
+		sb.WriteString("\nStages:")
+	}
+	for i, st := range rep.Stages {
+		sb.WriteString(fmt.Sprintf("\n  %d. %s: %d reqs, %.1f rps, p95 %s, %d errors",
+			i+1, st.Stage, st.Requests, st.RPS, formatMillis(st.LatencyMS.P95), st.Errors))
+	}
+	if len(rep.Thresholds) > 0 {
+		sb.WriteString("\nThresholds:")
+	}
+	for _, t := range rep.Thresholds {
+		if t.Pass {
+			sb.WriteString("\n" + thresholdPassStyle.Render(fmt.Sprintf("  ✓ %s (%s)", t.Threshold, t.FormatActual())))
+		} else {
+			sb.WriteString("\n" + thresholdFailStyle.Render(fmt.Sprintf("  ✗ %s (%s)", t.Threshold, t.FormatActual())))
+		}
+	}
+	return sb.String()
+}
+
+// viewRunCompare shows run b side by side with run a, with b's change over a.
+func viewRunCompare(a, b runs.Run, width int) string {
+	var sb strings.Builder
+	sb.WriteString("Compare runs (c on A: stop comparing)\n")
+	for _, r := range []struct {
+		label string
+		run   runs.Run
+	}{{"A", a}, {"B", b}} {
+		line := fmt.Sprintf("%s: %s  %s, %s", r.label, r.run.Time.Format(runTimeFormat),
+			r.run.Title(), r.run.Config.Describe())
+		sb.WriteString(truncate(line, width-2) + "\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\n%-14s %10s %10s %10s %8s", "", "A", "B", "Δ", "Δ%"))
+	for _, metric := range runs.Metrics {
+		va, vb := metric.Value(a.Report), metric.Value(b.Report)
+		delta := vb - va
+		pct := "-"
+		if va != 0 {
+			pct = fmt.Sprintf("%+.1f%%", 100*delta/va)
+		}
+		change := fmt.Sprintf("%+10.1f %8s", delta, pct)
+		// Green is better, red is worse; rounding noise is neither
+		if math.Abs(delta) >= 0.05 {
+			if (delta < 0) == metric.LowerIsBetter {
+				change = thresholdPassStyle.Render(change)
+			} else {
+				change = thresholdFailStyle.Render(change)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("\n%-14s %10.1f %10.1f %s", metric.Name, va, vb, change))
+	}
+	return sb.String()
+}
+
+// reportWindows rebuilds the per-second windows of a report for the dashboard graphs.
+func reportWindows(r load.Report) []load.WindowStats {
+	windows := make([]load.WindowStats, len(r.PerSecond))
+	for i, w := range r.PerSecond {
+		duration := time.Second
+		if w.RPS > 0 {
+			duration = time.Duration(float64(w.Requests) / w.RPS * float64(time.Second))
+		}
+		windows[i] = load.WindowStats{
+			Start:    time.Duration(w.StartS * float64(time.Second)),
+			Duration: duration,
+			Requests: w.Requests,
+			Failures: int(math.Round(w.ErrorRate * float64(w.Requests))),
+			Mean:     fromMillis(w.MeanMS),
+			P50:      fromMillis(w.P50MS),
+			P99:      fromMillis(w.P99MS),
+			InFlight: w.InFlight,
+		}
+	}
+	return windows
+}
+
+func fromMillis(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// formatCounts lists counts in key order, e.g. "200: 97, 500: 3".
+func formatCounts[K cmp.Ordered](counts map[K]int) string {
+	keys := slices.Sorted(maps.Keys(counts))
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%v: %d", k, counts[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatMillis formats a latency in milliseconds as a rounded duration.
+func formatMillis(ms float64) string {
+	return fromMillis(ms).Round(100 * time.Microsecond).String()
+}
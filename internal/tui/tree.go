@@ -0,0 +1,316 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// jsonKind is the type of a node in the JSON tree.
+type jsonKind int
+
+const (
+	jsonObject jsonKind = iota
+	jsonArray
+	jsonScalar
+)
+
+// jsonNode is one value of a JSON document. Object keys keep their document order.
+type jsonNode struct {
+	Kind     jsonKind
+	Label    string // Object key or array index, empty for the root
+	Path     string // jq-style path, e.g. .items[2].id
+	Value    string // JSON text of a scalar
+	Children []*jsonNode
+	Parent   *jsonNode
+	Depth    int
+	Expanded bool
+}
+
+// jsonTree is the collapsible tree view of a JSON body with a cursor over its visible rows.
+type jsonTree struct {
+	Root   *jsonNode
+	rows   []*jsonNode // Visible nodes, in display order
+	Cursor int
+}
+
+// newJSONTree parses body into a tree with the root expanded and everything below it folded.
+func newJSONTree(body string) (*jsonTree, error) {
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	root, err := decodeNode(dec, nil, "", ".")
+	if err != nil {
+		return nil, err
+	}
+	root.Expanded = true
+	t := &jsonTree{Root: root}
+	t.flatten()
+	return t, nil
+}
+
+func decodeNode(dec *json.Decoder, parent *jsonNode, label, path string) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{Label: label, Path: path, Parent: parent}
+	if parent != nil {
+		n.Depth = parent.Depth + 1
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		n.Kind = jsonArray
+		if t == '{' {
+			n.Kind = jsonObject
+		}
+		for i := 0; dec.More(); i++ {
+			var childLabel, childPath string
+			if n.Kind == jsonObject {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				childLabel = keyTok.(string)
+				childPath = joinPath(path, keyPath(childLabel))
+			} else {
+				childLabel = strconv.Itoa(i)
+				childPath = joinPath(path, "["+childLabel+"]")
+			}
+			child, err := decodeNode(dec, n, childLabel, childPath)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, child)
+		}
+		if _, err := dec.Token(); err != nil { // Closing delimiter
+			return nil, err
+		}
+	default:
+		n.Kind = jsonScalar
+		n.Value = scalarJSON(t)
+	}
+	return n, nil
+}
+
+// scalarJSON returns the JSON text of a decoded scalar token.
+func scalarJSON(tok json.Token) string {
+	switch v := tok.(type) {
+	case json.Number:
+		return v.String()
+	case string:
+		return quoteJSON(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return "null"
+}
+
+// quoteJSON quotes s as a JSON string, leaving <, > and & alone.
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+var identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// keyPath is the path segment selecting an object key.
+func keyPath(key string) string {
+	if identifierRe.MatchString(key) {
+		return "." + key
+	}
+	return "[" + quoteJSON(key) + "]"
+}
+
+func joinPath(parent, segment string) string {
+	if parent == "." {
+		if strings.HasPrefix(segment, ".") {
+			return segment
+		}
+		return "." + segment
+	}
+	return parent + segment
+}
+
+// flatten rebuilds the list of visible rows after nodes were folded or unfolded.
+func (t *jsonTree) flatten() {
+	t.rows = t.rows[:0]
+	var walk func(n *jsonNode)
+	walk = func(n *jsonNode) {
+		t.rows = append(t.rows, n)
+		if n.Expanded {
+			for _, c := range n.Children {
+				walk(c)
+			}
+		}
+	}
+	walk(t.Root)
+	t.Cursor = min(max(t.Cursor, 0), len(t.rows)-1)
+}
+
+// Selected returns the node under the cursor.
+func (t *jsonTree) Selected() *jsonNode {
+	return t.rows[t.Cursor]
+}
+
+// Move moves the cursor by delta rows.
+func (t *jsonTree) Move(delta int) {
+	t.Cursor = min(max(t.Cursor+delta, 0), len(t.rows)-1)
+}
+
+// Expand unfolds the selected node, or steps into it if it already is.
+func (t *jsonTree) Expand() {
+	n := t.Selected()
+	if n.Kind == jsonScalar || len(n.Children) == 0 {
+		return
+	}
+	if n.Expanded {
+		t.Move(1)
+		return
+	}
+	n.Expanded = true
+	t.flatten()
+}
+
+// Collapse folds the selected node, or moves to its parent if there's nothing to fold.
+func (t *jsonTree) Collapse() {
+	n := t.Selected()
+	if n.Expanded && n.Parent != nil {
+		n.Expanded = false
+		t.flatten()
+		return
+	}
+	if n.Parent != nil {
+		for i, row := range t.rows {
+			if row == n.Parent {
+				t.Cursor = i
+				break
+			}
+		}
+	}
+}
+
+// Summary describes the top-level value, listing the keys of an object.
+func (t *jsonTree) Summary() string {
+	switch t.Root.Kind {
+	case jsonObject:
+		keys := make([]string, len(t.Root.Children))
+		for i, c := range t.Root.Children {
+			keys[i] = c.Label
+		}
+		return fmt.Sprintf("object, %s: %s", plural(len(keys), "key"), strings.Join(keys, ", "))
+	case jsonArray:
+		return "array, " + plural(len(t.Root.Children), "item")
+	}
+	return "scalar"
+}
+
+// Render draws the visible rows, marking the one under the cursor.
+func (t *jsonTree) Render() string {
+	var sb strings.Builder
+	for i, n := range t.rows {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		if i == t.Cursor {
+			sb.WriteString(selectedItemStyle.Render(">"))
+		} else {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(strings.Repeat("  ", n.Depth))
+
+		switch {
+		case n.Kind == jsonScalar:
+			sb.WriteString("  ")
+		case n.Expanded:
+			sb.WriteString("▾ ")
+		default:
+			sb.WriteString("▸ ")
+		}
+
+		if n.Parent == nil {
+			sb.WriteString(jsonPunctStyle.Render("root "))
+		} else if n.Parent.Kind == jsonArray {
+			sb.WriteString(jsonPunctStyle.Render("[" + n.Label + "] "))
+		} else {
+			sb.WriteString(jsonKeyStyle.Render(quoteJSON(n.Label)) + jsonPunctStyle.Render(": "))
+		}
+
+		// Folded containers show an ellipsis for their content
+		opening, closing := "{", "}"
+		if n.Kind == jsonArray {
+			opening, closing = "[", "]"
+		}
+		if !n.Expanded {
+			opening += "…" + closing
+		}
+		switch n.Kind {
+		case jsonObject:
+			sb.WriteString(jsonPunctStyle.Render(opening+" ") + labelStyle.Render(plural(len(n.Children), "key")))
+		case jsonArray:
+			sb.WriteString(jsonPunctStyle.Render(opening+" ") + labelStyle.Render(plural(len(n.Children), "item")))
+		default:
+			sb.WriteString(highlightJSON(n.Value))
+		}
+	}
+	return sb.String()
+}
+
+// JSON returns the node's value as indented JSON, keeping key order.
+// Strings are returned unquoted, since that's what's wanted when copying one.
+func (n *jsonNode) JSON() string {
+	if n.Kind == jsonScalar {
+		var s string
+		if json.Unmarshal([]byte(n.Value), &s) == nil {
+			return s
+		}
+		return n.Value
+	}
+	var buf bytes.Buffer
+	n.writeJSON(&buf)
+	var out bytes.Buffer
+	if json.Indent(&out, buf.Bytes(), "", "  ") != nil {
+		return buf.String()
+	}
+	return out.String()
+}
+
+func (n *jsonNode) writeJSON(buf *bytes.Buffer) {
+	switch n.Kind {
+	case jsonScalar:
+		buf.WriteString(n.Value)
+	case jsonObject:
+		buf.WriteByte('{')
+		for i, c := range n.Children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(quoteJSON(c.Label))
+			buf.WriteByte(':')
+			c.writeJSON(buf)
+		}
+		buf.WriteByte('}')
+	case jsonArray:
+		buf.WriteByte('[')
+		for i, c := range n.Children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			c.writeJSON(buf)
+		}
+		buf.WriteByte(']')
+	}
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
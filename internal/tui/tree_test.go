@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"lazycurl/internal/jq"
+	"testing"
+)
+
+func TestKeyPathSelectsKeyWithJq(t *testing.T) {
+	keys := []string{"name", "_id2", "x y", "2nd", `say "hi"`, "a\x00b", "tab\there", "😀", "<&>", "über"}
+	obj := make(map[string]int, len(keys))
+	for i, k := range keys {
+		obj[k] = i
+	}
+	root, _ := json.Marshal(obj)
+	nested, _ := json.Marshal(map[string]any{"top": obj})
+
+	for i, k := range keys {
+		want, _ := json.Marshal(i)
+		for _, tt := range []struct{ body, parent string }{{string(root), "."}, {string(nested), ".top"}} {
+			path := joinPath(tt.parent, keyPath(k))
+			got, err := jq.Apply(context.Background(), tt.body, path)
+			if err != nil {
+				t.Errorf("path %s of key %q: %v", path, k, err)
+			} else if got != string(want) {
+				t.Errorf("path %s of key %q selects %s, want %s", path, k, got, want)
+			}
+		}
+	}
+}
@@ -1,13 +1,20 @@
 package tui
 
 import (
+	"context"
+	"fmt"
+	"lazycurl/internal/env"
+	"lazycurl/internal/history"
 	"lazycurl/internal/load"
 	"lazycurl/internal/model"
+	"lazycurl/internal/runs"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -21,6 +28,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Global Keybindings (only when NOT editing, or special keys)
 		if !m.IsEditing {
 			if key.Matches(msg, m.KeyMap.Quit) {
+				m.SaveRequests()
+				if !m.Quitting && (m.IsRunningRequest || m.LoadState.IsRunning) {
+					// Quit once they have stopped, so no curl process is left behind and
+					// the load test is saved; quitting again doesn't wait
+					m.Quitting = true
+					m.Status = "Stopping before quitting... (q again to force)"
+					if m.IsRunningRequest {
+						m.CancelRequest()
+					}
+					if m.LoadState.IsRunning {
+						m.LoadState.Cancel()
+					}
+					return m, nil
+				}
 				return m, tea.Quit
 			}
 			if key.Matches(msg, m.KeyMap.Tab) {
@@ -34,7 +55,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if key.Matches(msg, m.KeyMap.Run) {
 				// Special handling if in Load Tab
 				if m.ActivePane == PaneEditor && m.ActiveEditorTab == TabLoad {
+					if m.LoadState.IsRunning {
+						return m, nil // One test at a time; 'x' aborts it
+					}
 					m.SyncRequestToEditor()
+					m.SaveRequests()
 
 					// Parse inputs
 					conc, _ := strconv.Atoi(m.LoadConfig.Concurrency.Value())
@@ -45,41 +70,193 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if err != nil {
 						dur = 5 * time.Second
 					}
+					var rps float64
+					if v := strings.TrimSpace(m.LoadConfig.RPS.Value()); v != "" {
+						if rps, err = strconv.ParseFloat(v, 64); err != nil || rps <= 0 {
+							m.Status = fmt.Sprintf("Invalid target RPS %q", v)
+							return m, nil
+						}
+					}
+					profile, err := load.ParseProfile(m.LoadConfig.Stages.Value())
+					if err != nil {
+						m.Status = err.Error()
+						return m, nil
+					}
+					if len(profile.Stages) > 0 {
+						rps = 0 // The stages replace the flat settings
+					}
+					thresholds, err := load.ParseThresholds(m.LoadConfig.Thresholds.Value())
+					if err != nil {
+						m.Status = err.Error()
+						return m, nil
+					}
+
+					var feeder *load.Feeder
+					if path := strings.TrimSpace(m.LoadConfig.DataFile.Value()); path != "" {
+						strategy, end, _ := strings.Cut(m.LoadConfig.DataMode.Value(), ",")
+						feedStrategy, err := load.ParseFeedStrategy(strategy)
+						if err != nil {
+							m.Status = err.Error()
+							return m, nil
+						}
+						feedEnd, err := load.ParseFeedEnd(end)
+						if err != nil {
+							m.Status = err.Error()
+							return m, nil
+						}
+						if feeder, err = load.LoadFeeder(path, feedStrategy, feedEnd); err != nil {
+							m.Status = err.Error()
+							return m, nil
+						}
+					}
+
+					var fed []string
+					if feeder != nil {
+						fed = feeder.Columns
+					}
+					backend := strings.TrimSpace(m.LoadConfig.Backend.Value())
+					var scenario load.Scenario
+					var missing []string
+					if v := strings.TrimSpace(m.LoadConfig.Scenario.Value()); v != "" {
+						scenario, err = load.ParseScenario(v, func(nameOrID string) (model.Request, error) {
+							req, err := model.FindRequest(m.Requests, nameOrID)
+							if err != nil {
+								return req, err
+							}
+							req, undefined := env.ResolveExcept(req, m.Envs.ActiveVars(), fed)
+							missing = append(missing, undefined...)
+							if backend != "" {
+								req.Backend = backend
+							}
+							return req, nil
+						})
+						if err != nil {
+							m.Status = err.Error()
+							return m, nil
+						}
+					}
 
 					m.LoadState.IsRunning = true
 					m.LoadState.Stats = load.NewStats()
+					m.ShowRun = false
 
 					runner := load.NewRunner()
-					req := m.Requests[m.SelectedReqIdx]
+					runner.RPS = rps
+					runner.Profile = profile
+					runner.Thresholds = thresholds
+					runner.AbortOnFail = strings.HasPrefix(strings.ToLower(strings.TrimSpace(m.LoadConfig.AbortOnFail.Value())), "y")
+					runner.Feeder = feeder
+					runner.Scenario = scenario
+					var req model.Request
+					if len(scenario.Steps) > 0 {
+						m.Status = ""
+						if len(missing) > 0 {
+							slices.Sort(missing)
+							m.Status = "Warning: undefined variables: " + strings.Join(slices.Compact(missing), ", ")
+						}
+					} else {
+						req = m.resolvedRequest(fed...)
+						if backend != "" {
+							req.Backend = backend
+						}
+					}
+
+					config := runs.Config{
+						Concurrency: conc,
+						Duration:    dur,
+						RPS:         rps,
+						Stages:      strings.TrimSpace(m.LoadConfig.Stages.Value()),
+						AbortOnFail: runner.AbortOnFail,
+						Scenario:    strings.TrimSpace(m.LoadConfig.Scenario.Value()),
+					}
+					if len(profile.Stages) > 0 {
+						config.Duration = profile.Duration()
+					}
+					for _, t := range thresholds {
+						config.Thresholds = append(config.Thresholds, t.Raw)
+					}
+					if feeder != nil {
+						config.DataFile, config.DataStrategy, config.DataEnd = strings.TrimSpace(m.LoadConfig.DataFile.Value()), feeder.Strategy, feeder.End
+					}
+					m.LoadState.Started, m.LoadState.Request, m.LoadState.Config = time.Now(), req, config
+					m.LoadState.Scenario = scenario.Steps
+
+					if m.LoadState.Samples != nil {
+						m.LoadState.Samples.Close()
+					}
+					m.LoadState.Samples, err = load.NewSampleLog()
+					if err != nil {
+						m.Status = err.Error() // The test can still run; only exporting samples is lost
+					}
+					runner.Samples = m.LoadState.Samples
 
 					// Start
-					ch := runner.Run(req, conc, dur)
+					ctx, cancel := context.WithCancel(context.Background())
+					ch := runner.Run(ctx, req, conc, dur)
 					m.LoadState.Sub = ch
+					m.LoadState.Cancel = cancel
 
 					m.ActivePane = PaneResponse // Switch to dashboard
 
-					return m, WaitForStats(ch)
+					return m, tea.Batch(WaitForStats(ch), m.Spinner.Tick)
 				} else {
+					if m.IsRunningRequest {
+						return m, nil // One request at a time; 'x' cancels it
+					}
 					m.SyncRequestToEditor()
-					return m, m.RunRequestCmd
+					m.SaveRequests()
+
+					ctx, cancel := context.WithCancel(context.Background())
+					m.IsRunningRequest = true
+					m.ShowRun = false
+					m.CancelRequest = cancel
+					return m, tea.Batch(m.RunRequestCmd(ctx, m.resolvedRequest()), m.Spinner.Tick)
 				}
 			}
+			if key.Matches(msg, m.KeyMap.Env) {
+				m.Envs.Next()
+				m.SaveEnvironments()
+				return m, nil
+			}
+			if key.Matches(msg, m.KeyMap.Cancel) && m.IsRunningRequest {
+				m.CancelRequest()
+				return m, nil
+			}
+			if key.Matches(msg, m.KeyMap.Cancel) && m.LoadState.IsRunning {
+				// Partial stats arrive with the final Done message
+				m.LoadState.Cancel()
+				return m, nil
+			}
 		} else {
 			// Special handling while editing
 			if key.Matches(msg, m.KeyMap.EditEsc) {
 				m.IsEditing = false
 				// Blur all
-				m.EditorInputs[0].Blur()
-				m.EditorInputs[1].Blur()
+				for i := range m.EditorInputs {
+					m.EditorInputs[i].Blur()
+				}
 				m.EditorBody.Blur()
+				m.BodyContentType.Blur()
+				m.BodyFile.Blur()
 				for i := range m.HeaderInputs {
 					m.HeaderInputs[i].Key.Blur()
 					m.HeaderInputs[i].Value.Blur()
 				}
-				m.LoadConfig.Concurrency.Blur()
-				m.LoadConfig.Duration.Blur()
+				for i := range m.FormInputs {
+					m.FormInputs[i].Key.Blur()
+					m.FormInputs[i].Value.Blur()
+				}
+				for _, f := range m.formFields() {
+					f.Input.Blur()
+				}
+				m.HistoryFilter.Blur()
+				m.ImportInput.Blur()
+				m.IsImporting = false
+				m.ResponseFilter.Blur()
+				m.IsFilteringBody = false
 
 				m.SyncRequestToEditor() // Save on exit edit
+				m.SaveRequests()
 				return m, nil
 			}
 		}
@@ -93,29 +270,99 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m, cmd = m.updateEditor(msg)
 			cmds = append(cmds, cmd)
 		case PaneResponse:
-			// Scrolling logic could go here
+			m, cmd = m.updateResponse(msg)
+			cmds = append(cmds, cmd)
 		}
 
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
 		m.Help.Width = msg.Width
+		m.refreshResponseView()
 	case load.StatsMsg:
 		m.LoadState.Stats = msg.Stats
 		if msg.Done {
 			m.LoadState.IsRunning = false
 			m.LoadState.Sub = nil // Clear channel
+			m.LoadState.Cancel()  // Release the context
+			m.LoadState.Cancel = nil
+			m.saveRun(msg.Stats)
+			cmds = append(cmds, m.quitWhenStopped())
 		} else {
 			return m, WaitForStats(m.LoadState.Sub) // Wait for next
 		}
-	case model.Response:
-		m.Response = &msg
+	case requestDoneMsg:
+		m.setResponse(&msg.Response)
+		m.HistoryEntries = append([]history.Entry{msg.Entry}, m.HistoryEntries...)
+		if len(m.HistoryEntries) > historyLimit {
+			m.HistoryEntries = m.HistoryEntries[:historyLimit]
+		}
+		if msg.HistoryErr != nil {
+			m.Status = msg.HistoryErr.Error()
+		}
+		m.IsRunningRequest = false
+		if m.CancelRequest != nil {
+			m.CancelRequest() // Release the context
+			m.CancelRequest = nil
+		}
+		cmds = append(cmds, m.quitWhenStopped())
+	case clipboardClearMsg:
+		if m.Clipboard == msg.Seq {
+			m.Clipboard = ""
+		}
+	case importDoneMsg:
+		if msg.Err != nil {
+			m.Status = "Import failed: " + msg.Err.Error()
+			return m, nil
+		}
+		m.Requests = msg.Result.MergeInto(m.Requests, m.Envs)
+		if m.SaveRequests() != nil || m.SaveEnvironments() != nil {
+			return m, nil
+		}
+		// The status bar is a single line
+		m.Status = strings.Replace(strings.ReplaceAll(msg.Result.Summary(), "\n  - ", "; "), ":;", ":", 1)
+	case spinner.TickMsg:
+		if m.IsRunningRequest || m.LoadState.IsRunning {
+			m.Spinner, cmd = m.Spinner.Update(msg)
+			return m, cmd
+		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
 func (m Model) updateRequests(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if !m.IsEditing && key.Matches(msg, m.KeyMap.Section) {
+		m.ActiveSection = (m.ActiveSection + 1) % 3
+		m.ShowRun = m.ActiveSection == SectionRuns && len(m.RunEntries) > 0
+		if m.ActiveSection == SectionHistory {
+			m.showHistoryEntry()
+		}
+		return m, nil
+	}
+	switch m.ActiveSection {
+	case SectionHistory:
+		return m.updateHistory(msg)
+	case SectionRuns:
+		return m.updateRuns(msg)
+	}
+
+	// Typing the import path
+	if m.IsImporting {
+		if msg.Type == tea.KeyEnter {
+			path := m.ImportInput.Value()
+			m.IsImporting = false
+			m.IsEditing = false
+			m.ImportInput.Blur()
+			m.ImportInput.SetValue("")
+			m.Status = "Importing " + path + "..."
+			return m, ImportPostmanCmd(path)
+		}
+		var cmd tea.Cmd
+		m.ImportInput, cmd = m.ImportInput.Update(msg)
+		return m, cmd
+	}
+
 	if key.Matches(msg, m.KeyMap.Up) {
 		if m.SelectedReqIdx > 0 {
 			m.SelectedReqIdx--
@@ -132,6 +379,11 @@ func (m Model) updateRequests(msg tea.KeyMsg) (Model, tea.Cmd) {
 		m.Requests = append(m.Requests, newReq)
 		m.SelectedReqIdx = len(m.Requests) - 1
 		m.SyncEditorToRequest()
+		m.SaveRequests()
+	} else if key.Matches(msg, m.KeyMap.Import) {
+		m.IsImporting = true
+		m.IsEditing = true
+		return m, m.ImportInput.Focus()
 	} else if key.Matches(msg, m.KeyMap.Delete) {
 		if len(m.Requests) > 1 {
 			m.Requests = append(m.Requests[:m.SelectedReqIdx], m.Requests[m.SelectedReqIdx+1:]...)
@@ -139,11 +391,106 @@ func (m Model) updateRequests(msg tea.KeyMsg) (Model, tea.Cmd) {
 				m.SelectedReqIdx = len(m.Requests) - 1
 			}
 			m.SyncEditorToRequest()
+			m.SaveRequests()
 		}
 	}
 	return m, nil
 }
 
+func (m Model) updateHistory(msg tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	// Typing a filter
+	if m.IsEditing {
+		if msg.Type == tea.KeyEnter {
+			m.IsEditing = false
+			m.HistoryFilter.Blur()
+			return m, nil
+		}
+		m.HistoryFilter, cmd = m.HistoryFilter.Update(msg)
+		m.SelectedHistIdx = 0
+		m.showHistoryEntry()
+		return m, cmd
+	}
+
+	if key.Matches(msg, m.KeyMap.Up) {
+		if m.SelectedHistIdx > 0 {
+			m.SelectedHistIdx--
+			m.showHistoryEntry()
+		}
+	} else if key.Matches(msg, m.KeyMap.Down) {
+		if m.SelectedHistIdx < len(m.filteredHistory())-1 {
+			m.SelectedHistIdx++
+			m.showHistoryEntry()
+		}
+	} else if key.Matches(msg, m.KeyMap.Filter) {
+		m.IsEditing = true
+		cmd = m.HistoryFilter.Focus()
+	} else if key.Matches(msg, m.KeyMap.Restore) {
+		// Restore into the editor as a new request, leaving the original untouched
+		if entry, ok := m.selectedHistoryEntry(); ok {
+			req := entry.Request
+			req.ID = model.NewID()
+			m.Requests = append(m.Requests, req)
+			m.SelectedReqIdx = len(m.Requests) - 1
+			m.SyncEditorToRequest()
+			m.SaveRequests()
+			m.ActiveSection = SectionRequests
+			m.ActivePane = PaneEditor
+		}
+	}
+	return m, cmd
+}
+
+func (m Model) updateRuns(msg tea.KeyMsg) (Model, tea.Cmd) {
+	run, ok := m.selectedRun()
+	if !ok {
+		return m, nil
+	}
+
+	if key.Matches(msg, m.KeyMap.Up) {
+		if m.SelectedRunIdx > 0 {
+			m.SelectedRunIdx--
+		}
+	} else if key.Matches(msg, m.KeyMap.Down) {
+		if m.SelectedRunIdx < len(m.RunEntries)-1 {
+			m.SelectedRunIdx++
+		}
+	} else if key.Matches(msg, m.KeyMap.Compare) {
+		if m.CompareRunID == run.ID {
+			m.CompareRunID = ""
+			m.Status = ""
+		} else {
+			m.CompareRunID = run.ID
+			m.Status = "Marked run " + run.Time.Format("Jan 2 15:04:05") + "; select another run to compare"
+		}
+	} else if key.Matches(msg, m.KeyMap.Delete) {
+		if m.Runs != nil {
+			if err := m.Runs.Delete(run.ID); err != nil {
+				m.Status = err.Error()
+				return m, nil
+			}
+		}
+		m.RunEntries = append(m.RunEntries[:m.SelectedRunIdx], m.RunEntries[m.SelectedRunIdx+1:]...)
+		if m.SelectedRunIdx >= len(m.RunEntries) {
+			m.SelectedRunIdx = max(len(m.RunEntries)-1, 0)
+		}
+		if m.CompareRunID == run.ID {
+			m.CompareRunID = ""
+		}
+	}
+	m.ShowRun = len(m.RunEntries) > 0
+	return m, nil
+}
+
+// showHistoryEntry shows the response of the selected history entry in the response pane.
+func (m *Model) showHistoryEntry() {
+	if entry, ok := m.selectedHistoryEntry(); ok {
+		resp := entry.Response()
+		m.setResponse(&resp)
+	}
+}
+
 func (m Model) updateEditor(msg tea.KeyMsg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 
@@ -151,13 +498,19 @@ func (m Model) updateEditor(msg tea.KeyMsg) (Model, tea.Cmd) {
 	if m.IsEditing {
 		// Hand off to bubbles
 		switch m.FocusedField {
+		case FieldName:
+			m.EditorInputs[2], cmd = m.EditorInputs[2].Update(msg)
 		case FieldMethod:
 			m.EditorInputs[0], cmd = m.EditorInputs[0].Update(msg)
 		case FieldURL:
 			m.EditorInputs[1], cmd = m.EditorInputs[1].Update(msg)
 		case FieldContent:
 			if m.ActiveEditorTab == TabBody {
-				m.EditorBody, cmd = m.EditorBody.Update(msg)
+				if input := m.focusedBodyInput(); input != nil {
+					*input, cmd = input.Update(msg)
+				} else {
+					m.EditorBody, cmd = m.EditorBody.Update(msg)
+				}
 			} else if m.ActiveEditorTab == TabHeaders {
 				// Header Editing
 				idx := m.FocusedHeaderIdx
@@ -168,13 +521,9 @@ func (m Model) updateEditor(msg tea.KeyMsg) (Model, tea.Cmd) {
 						m.HeaderInputs[idx].Value, cmd = m.HeaderInputs[idx].Value.Update(msg)
 					}
 				}
-			} else if m.ActiveEditorTab == TabLoad {
-				// Load Config Editing
-				if m.FocusedHeaderIdx == 0 {
-					m.LoadConfig.Concurrency, cmd = m.LoadConfig.Concurrency.Update(msg)
-				} else {
-					m.LoadConfig.Duration, cmd = m.LoadConfig.Duration.Update(msg)
-				}
+			} else if input := m.focusedFormField(); input != nil {
+				// Settings / Load Config Editing
+				*input, cmd = input.Update(msg)
 			}
 		}
 		return m, cmd
@@ -195,13 +544,13 @@ func (m Model) updateEditor(msg tea.KeyMsg) (Model, tea.Cmd) {
 				return m, nil // Handled inside content
 			}
 		}
-		if m.FocusedField == FieldContent && m.ActiveEditorTab == TabLoad {
+		if m.FocusedField == FieldContent && (m.ActiveEditorTab == TabBody || len(m.formFields()) > 0) {
 			if m.FocusedHeaderIdx > 0 {
 				m.FocusedHeaderIdx--
 				return m, nil
 			}
 		}
-		if m.FocusedField > FieldMethod {
+		if m.FocusedField > FieldName {
 			m.FocusedField--
 		}
 	case "down", "j":
@@ -212,8 +561,14 @@ func (m Model) updateEditor(msg tea.KeyMsg) (Model, tea.Cmd) {
 				return m, nil // Handled inside content
 			}
 		}
-		if m.FocusedField == FieldContent && m.ActiveEditorTab == TabLoad {
-			if m.FocusedHeaderIdx < 1 { // Only 2 inputs
+		if m.FocusedField == FieldContent && len(m.formFields()) > 0 {
+			if m.FocusedHeaderIdx < len(m.formFields())-1 {
+				m.FocusedHeaderIdx++
+				return m, nil
+			}
+		}
+		if m.FocusedField == FieldContent && m.ActiveEditorTab == TabBody {
+			if m.FocusedHeaderIdx < m.bodyRows()-1 {
 				m.FocusedHeaderIdx++
 				return m, nil
 			}
@@ -223,32 +578,32 @@ func (m Model) updateEditor(msg tea.KeyMsg) (Model, tea.Cmd) {
 		}
 	case "left", "h":
 		if m.FocusedField == FieldTabs {
-			if m.ActiveEditorTab == TabHeaders {
-				// Already leftmost
-			} else if m.ActiveEditorTab == TabBody {
-				m.ActiveEditorTab = TabHeaders // Move left
-			} else if m.ActiveEditorTab == TabLoad {
-				m.ActiveEditorTab = TabBody // Move left
+			if m.ActiveEditorTab > TabHeaders {
+				m.ActiveEditorTab-- // Move left
+				m.FocusedHeaderIdx = 0
 			}
-		} else if m.FocusedField == FieldContent && m.ActiveEditorTab == TabHeaders {
+		} else if m.FocusedField == FieldContent && m.editedPairs() != nil {
 			m.FocusedHeaderKey = true
 		}
 	case "right", "l":
 		if m.FocusedField == FieldTabs {
-			if m.ActiveEditorTab == TabHeaders {
-				m.ActiveEditorTab = TabBody // Move right
-			} else if m.ActiveEditorTab == TabBody {
-				m.ActiveEditorTab = TabLoad // Move right
+			if m.ActiveEditorTab < tabCount-1 {
+				m.ActiveEditorTab++ // Move right
+				m.FocusedHeaderIdx = 0
 			}
-		} else if m.FocusedField == FieldContent && m.ActiveEditorTab == TabHeaders {
+		} else if m.FocusedField == FieldContent && m.editedPairs() != nil {
 			m.FocusedHeaderKey = false
 		}
 	case "enter":
 		if m.FocusedField == FieldTabs {
 			// Toggle active tab via cycling?
-			m.ActiveEditorTab = (m.ActiveEditorTab + 1) % 3
+			m.ActiveEditorTab = (m.ActiveEditorTab + 1) % tabCount
+			m.FocusedHeaderIdx = 0
 		} else {
 			m.IsEditing = true
+			if m.FocusedField == FieldName {
+				cmd = m.EditorInputs[2].Focus()
+			}
 			if m.FocusedField == FieldMethod {
 				cmd = m.EditorInputs[0].Focus()
 			}
@@ -257,7 +612,11 @@ func (m Model) updateEditor(msg tea.KeyMsg) (Model, tea.Cmd) {
 			}
 			if m.FocusedField == FieldContent {
 				if m.ActiveEditorTab == TabBody {
-					cmd = m.EditorBody.Focus()
+					if input := m.focusedBodyInput(); input != nil {
+						cmd = input.Focus()
+					} else {
+						cmd = m.EditorBody.Focus()
+					}
 				} else if m.ActiveEditorTab == TabHeaders {
 					// Focus active header input
 					idx := m.FocusedHeaderIdx
@@ -268,58 +627,226 @@ func (m Model) updateEditor(msg tea.KeyMsg) (Model, tea.Cmd) {
 							cmd = m.HeaderInputs[idx].Value.Focus()
 						}
 					}
-				} else if m.ActiveEditorTab == TabLoad {
-					if m.FocusedHeaderIdx == 0 {
-						cmd = m.LoadConfig.Concurrency.Focus()
-					} else {
-						cmd = m.LoadConfig.Duration.Focus()
-					}
+				} else if input := m.focusedFormField(); input != nil {
+					cmd = input.Focus()
 				}
 			}
 		}
 	case "n":
-		// Add new header logic
-		if m.ActiveEditorTab == TabHeaders && m.FocusedField == FieldContent {
-			kInput := textinput.New()
-			kInput.Placeholder = "Header"
-			vInput := textinput.New()
-			vInput.Placeholder = "Value"
-			m.HeaderInputs = append(m.HeaderInputs, InputPair{Key: kInput, Value: vInput})
-			m.FocusedHeaderIdx = len(m.HeaderInputs) - 1
+		// Add new header or form field logic
+		if pairs := m.editedPairs(); pairs != nil && m.FocusedField == FieldContent {
+			*pairs = append(*pairs, m.newPair())
+			m.FocusedHeaderIdx = len(*pairs) - 1
 			m.FocusedHeaderKey = true
 			m.IsEditing = true
-			cmd = m.HeaderInputs[m.FocusedHeaderIdx].Key.Focus()
+			cmd = (*pairs)[m.FocusedHeaderIdx].Key.Focus()
 		}
 	case "d":
-		// Delete header logic
-		if m.ActiveEditorTab == TabHeaders && m.FocusedField == FieldContent {
-			if len(m.HeaderInputs) > 0 {
-				m.HeaderInputs = append(m.HeaderInputs[:m.FocusedHeaderIdx], m.HeaderInputs[m.FocusedHeaderIdx+1:]...)
-				if m.FocusedHeaderIdx >= len(m.HeaderInputs) {
-					m.FocusedHeaderIdx = len(m.HeaderInputs) - 1
+		// Delete header or form field logic
+		if pairs := m.editedPairs(); pairs != nil && m.FocusedField == FieldContent {
+			if len(*pairs) > 0 {
+				*pairs = append((*pairs)[:m.FocusedHeaderIdx], (*pairs)[m.FocusedHeaderIdx+1:]...)
+				if m.FocusedHeaderIdx >= len(*pairs) {
+					m.FocusedHeaderIdx = len(*pairs) - 1
 				}
 				if m.FocusedHeaderIdx < 0 {
 					m.FocusedHeaderIdx = 0
 				}
 				// If empty, add one back
-				if len(m.HeaderInputs) == 0 {
-					kInput := textinput.New()
-					kInput.Placeholder = "Header"
-					vInput := textinput.New()
-					vInput.Placeholder = "Value"
-					m.HeaderInputs = append(m.HeaderInputs, InputPair{Key: kInput, Value: vInput})
+				if len(*pairs) == 0 {
+					*pairs = append(*pairs, m.newPair())
 				}
 			}
 		}
+	case "m":
+		// Cycle the body mode
+		if m.ActiveEditorTab == TabBody && m.FocusedField == FieldContent {
+			i := slices.Index(model.BodyModes, m.BodyMode)
+			m.BodyMode = model.BodyModes[(i+1)%len(model.BodyModes)]
+			m.FocusedHeaderIdx = 0
+			m.FocusedHeaderKey = true
+			m.SyncRequestToEditor()
+			m.SaveRequests()
+		}
 	}
 
 	return m, cmd
 }
 
-// RunRequestCmd executes the current request.
-func (m Model) RunRequestCmd() tea.Msg {
-	if len(m.Requests) == 0 {
-		return nil
+func (m Model) updateResponse(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.ShowRun || m.showDashboard() {
+		if key.Matches(msg, m.KeyMap.NextGraph) {
+			m.LoadState.Graph = (m.LoadState.Graph + 1) % len(dashboardGraphs)
+		}
+		if key.Matches(msg, m.KeyMap.Export) {
+			m.exportLoad()
+		}
+		return m, nil // The response behind the dashboard isn't shown
+	}
+
+	// Typing the filter; the body is re-filtered as it changes
+	if m.IsFilteringBody {
+		if msg.Type == tea.KeyEnter {
+			m.IsFilteringBody = false
+			m.IsEditing = false
+			m.ResponseFilter.Blur()
+			m.SyncRequestToEditor() // Remember the filter with the request
+			m.SaveRequests()
+			return m, nil
+		}
+		prev := m.ResponseFilter.Value()
+		var cmd tea.Cmd
+		m.ResponseFilter, cmd = m.ResponseFilter.Update(msg)
+		if m.ResponseFilter.Value() != prev {
+			m.applyResponseFilter()
+			m.refreshResponseView()
+			m.ResponseViewport.GotoTop()
+		}
+		return m, cmd
+	}
+	if key.Matches(msg, m.KeyMap.BodyFilter) {
+		m.ActiveResponseView = ResponseBody
+		m.RawBody = false
+		m.TreeMode = false
+		m.IsFilteringBody = true
+		m.IsEditing = true
+		m.refreshResponseView()
+		return m, m.ResponseFilter.Focus()
+	}
+
+	if key.Matches(msg, m.KeyMap.SwitchView) {
+		m.ActiveResponseView = (m.ActiveResponseView + 1) % 3
+		m.refreshResponseView()
+		m.ResponseViewport.GotoTop()
+		return m, nil
+	}
+	if key.Matches(msg, m.KeyMap.RawBody) {
+		m.RawBody = !m.RawBody
+		m.TreeMode = false
+		m.refreshResponseView()
+		return m, nil
+	}
+	if key.Matches(msg, m.KeyMap.Tree) && m.ActiveResponseView == ResponseBody {
+		m.TreeMode = !m.TreeMode
+		m.refreshResponseView()
+		if m.TreeMode && m.Tree == nil {
+			m.TreeMode = false
+			m.Status = "Tree view needs a JSON body"
+		}
+		return m, nil
+	}
+	if m.TreeMode && m.Tree != nil && m.ActiveResponseView == ResponseBody {
+		return m.updateTree(msg)
+	}
+
+	var cmd tea.Cmd
+	m.ResponseViewport, cmd = m.ResponseViewport.Update(msg)
+	return m, cmd
+}
+
+// updateTree handles keys while exploring the body as a JSON tree.
+func (m Model) updateTree(msg tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch {
+	case key.Matches(msg, m.KeyMap.Up):
+		m.Tree.Move(-1)
+	case key.Matches(msg, m.KeyMap.Down):
+		m.Tree.Move(1)
+	case key.Matches(msg, m.ResponseViewport.KeyMap.PageUp):
+		m.Tree.Move(-m.ResponseViewport.Height)
+	case key.Matches(msg, m.ResponseViewport.KeyMap.PageDown):
+		m.Tree.Move(m.ResponseViewport.Height)
+	case key.Matches(msg, m.KeyMap.Collapse):
+		m.Tree.Collapse()
+	case key.Matches(msg, m.KeyMap.Expand):
+		m.Tree.Expand()
+	case key.Matches(msg, m.KeyMap.CopyPath):
+		path := m.Tree.Selected().Path
+		m.Status = "Copied path " + path
+		cmd = m.copyToClipboard(path)
+	case key.Matches(msg, m.KeyMap.CopyValue):
+		node := m.Tree.Selected()
+		value := node.JSON()
+		m.Status = fmt.Sprintf("Copied value of %s (%d B)", node.Path, len(value))
+		cmd = m.copyToClipboard(value)
+	default:
+		return m, nil
+	}
+	m.refreshResponseView()
+	return m, cmd
+}
+
+// setResponse shows resp in the response pane, scrolled to the top.
+func (m *Model) setResponse(resp *model.Response) {
+	m.Response = resp
+	m.Tree = nil
+	m.applyResponseFilter()
+	m.refreshResponseView()
+	m.ResponseViewport.GotoTop()
+}
+
+// refreshResponseView re-renders the active response view into the viewport,
+// e.g. after the response, the view or the window size changed.
+func (m *Model) refreshResponseView() {
+	width, height := m.paneSize()
+	// Leave room for the Status/Time/Size and tab lines above the content
+	m.ResponseViewport.Width = width
+	m.ResponseViewport.Height = max(height-6, 1)
+	if m.Response == nil {
+		m.ResponseViewport.SetContent("")
+		return
+	}
+
+	switch m.ActiveResponseView {
+	case ResponseHeaders:
+		m.ResponseViewport.SetContent(viewHeaders(m.Response.Headers))
+	case ResponseTiming:
+		m.ResponseViewport.SetContent(viewTiming(*m.Response, width-4))
+	default:
+		if m.TreeMode {
+			if m.Tree == nil && isJSON(m.Response.Body, m.Response.Headers) {
+				m.Tree, _ = newJSONTree(m.Response.Body)
+			}
+			if m.Tree != nil {
+				m.ResponseViewport.SetContent(labelStyle.Render(m.Tree.Summary()) + "\n\n" + m.Tree.Render())
+				// Keep the cursor in view; the tree starts below the summary line
+				line := m.Tree.Cursor + 2
+				if line < m.ResponseViewport.YOffset {
+					m.ResponseViewport.SetYOffset(line)
+				} else if line >= m.ResponseViewport.YOffset+m.ResponseViewport.Height {
+					m.ResponseViewport.SetYOffset(line - m.ResponseViewport.Height + 1)
+				}
+				return
+			}
+		}
+		if !m.RawBody && m.FilterErr != nil {
+			// Show what's wrong above the unfiltered body
+			m.ResponseViewport.SetContent(filterErrorStyle.Render(m.FilterErr.Error()) + "\n\n" +
+				formatBody(m.Response.Body, m.Response.Headers, false))
+			return
+		}
+		if !m.RawBody && strings.TrimSpace(m.ResponseFilter.Value()) != "" {
+			if m.FilterOutput == "" {
+				m.ResponseViewport.SetContent(labelStyle.Render("No results."))
+			} else {
+				m.ResponseViewport.SetContent(withLineNumbers(highlightJSON(m.FilterOutput)))
+			}
+			return
+		}
+		m.ResponseViewport.SetContent(formatBody(m.Response.Body, m.Response.Headers, m.RawBody))
+	}
+}
+
+// RunRequestCmd executes a (resolved) request and records it in history. Cancelling ctx aborts it.
+func (m Model) RunRequestCmd(ctx context.Context, req model.Request) tea.Cmd {
+	executor := m.Executor
+	store := m.History
+	return func() tea.Msg {
+		resp := executor.Execute(ctx, req)
+		msg := requestDoneMsg{Response: resp, Entry: history.NewEntry(req, resp)}
+		if store != nil {
+			msg.HistoryErr = store.Append(msg.Entry)
+		}
+		return msg
 	}
-	return m.Executor.Execute(m.Requests[m.SelectedReqIdx])
 }
@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"context"
+	"lazycurl/internal/env"
+	"lazycurl/internal/load"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var quitKey = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}
+
+// quits reports whether cmd, or any command batched in it, quits the program.
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	switch msg := cmd().(type) {
+	case tea.QuitMsg:
+		return true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if quits(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestQuitStopsLoadTestFirst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := Model{KeyMap: DefaultKeyMap(), Envs: &env.Set{}}
+	m.LoadState = LoadState{IsRunning: true, Cancel: cancel, Sub: make(chan load.StatsMsg)}
+
+	next, cmd := m.Update(quitKey)
+	m = next.(Model)
+	if quits(cmd) {
+		t.Fatal("quit while the load test was still running")
+	}
+	if ctx.Err() == nil {
+		t.Fatal("quitting didn't cancel the load test")
+	}
+
+	s := load.NewStats()
+	s.TotalRequests = 3
+	next, cmd = m.Update(load.StatsMsg{Stats: s, Done: true})
+	m = next.(Model)
+	if !quits(cmd) {
+		t.Error("didn't quit once the load test stopped")
+	}
+	if len(m.RunEntries) != 1 {
+		t.Errorf("%d runs recorded, want the stopped test", len(m.RunEntries))
+	}
+}
+
+func TestQuitCancelsRequestFirst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := Model{KeyMap: DefaultKeyMap(), Envs: &env.Set{}, IsRunningRequest: true, CancelRequest: cancel}
+
+	next, cmd := m.Update(quitKey)
+	m = next.(Model)
+	if quits(cmd) || ctx.Err() == nil {
+		t.Fatalf("quit %v, request cancelled %v; want false, true", quits(cmd), ctx.Err() != nil)
+	}
+
+	// Quitting again doesn't wait
+	if _, cmd := m.Update(quitKey); !quits(cmd) {
+		t.Error("second quit didn't force quitting")
+	}
+
+	next, cmd = m.Update(requestDoneMsg{})
+	if !quits(cmd) {
+		t.Error("didn't quit once the request stopped")
+	}
+	if next.(Model).IsRunningRequest {
+		t.Error("request still marked running")
+	}
+}
+
+func TestQuitWhenIdle(t *testing.T) {
+	m := Model{KeyMap: DefaultKeyMap(), Envs: &env.Set{}}
+	if _, cmd := m.Update(quitKey); !quits(cmd) {
+		t.Error("quit with nothing running didn't quit")
+	}
+}
+
+func TestCopyGoesOutWithTheView(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	m, err := NewModel(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Width, m.Height = 120, 40
+
+	cmd := m.copyToClipboard("hi")
+	const seq = "\x1b]52;c;aGk=\a"
+	if m.Clipboard != seq {
+		t.Fatalf("sequence %q, want %q", m.Clipboard, seq)
+	}
+	if view := m.View(); !strings.HasPrefix(view, seq) {
+		t.Errorf("view doesn't carry the copy: %.40q", view)
+	}
+
+	// A later copy isn't cleared by the earlier one's timer
+	stale := cmd()
+	m.copyToClipboard("again")
+	next, _ := m.Update(stale)
+	if m = next.(Model); m.Clipboard == "" {
+		t.Error("an earlier copy cleared a later one")
+	}
+	next, _ = m.Update(clipboardClearMsg{Seq: m.Clipboard})
+	if m = next.(Model); m.Clipboard != "" || strings.Contains(m.View(), "\x1b]52") {
+		t.Error("copy sequence still drawn after clearing")
+	}
+}
@@ -1,7 +1,16 @@
 package tui
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"lazycurl/internal/load"
+	"lazycurl/internal/model"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,21 +36,15 @@ var (
 
 	labelStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginBottom(0)
 	activeLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62")).Bold(true).MarginBottom(0)
+
+	thresholdPassStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))  // Green
+	thresholdFailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")) // Red
+	currentStageStyle  = lipgloss.NewStyle().Bold(true)
 )
 
 // View renders the TUI.
 func (m Model) View() string {
-	// Calculate pane widths
-	// MVP: Equal split for 3 panes approx
-	paneWidth := (m.Width - 6) / 3
-	paneHeight := m.Height - 8 // Reserve space for help
-
-	if paneWidth < 10 {
-		paneWidth = 10
-	}
-	if paneHeight < 5 {
-		paneHeight = 5
-	}
+	paneWidth, paneHeight := m.paneSize()
 
 	requestsView := m.viewRequests(paneWidth, paneHeight)
 	editorView := m.viewEditor(paneWidth, paneHeight)
@@ -51,9 +54,32 @@ func (m Model) View() string {
 	mainPanes := lipgloss.JoinHorizontal(lipgloss.Top, requestsView, editorView, responseView)
 
 	// Help Bar
-	helpView := m.Help.View(m.KeyMap)
+	envName := m.Envs.Active
+	if envName == "" {
+		envName = "none"
+	}
+	helpView := activeLabelStyle.Render("env: "+envName) + "  " + m.Help.View(m.KeyMap)
+	if m.Status != "" {
+		helpView = labelStyle.Render(m.Status) + "\n" + helpView
+	}
+
+	// A pending copy rides along with the frame; the escape sequence takes no space
+	return m.Clipboard + docStyle.Render(lipgloss.JoinVertical(lipgloss.Left, mainPanes, helpView))
+}
 
-	return docStyle.Render(lipgloss.JoinVertical(lipgloss.Left, mainPanes, helpView))
+// paneSize returns the width and height of each of the three panes.
+func (m Model) paneSize() (int, int) {
+	// MVP: Equal split for 3 panes approx
+	paneWidth := (m.Width - 6) / 3
+	paneHeight := m.Height - 8 // Reserve space for help
+
+	if paneWidth < 10 {
+		paneWidth = 10
+	}
+	if paneHeight < 5 {
+		paneHeight = 5
+	}
+	return paneWidth, paneHeight
 }
 
 func (m Model) viewRequests(width, height int) string {
@@ -62,29 +88,116 @@ func (m Model) viewRequests(width, height int) string {
 		style = focusedStyle
 	}
 
+	tabRequests := "Requests"
+	tabHistory := "History"
+	tabRuns := "Runs"
+	switch m.ActiveSection {
+	case SectionRequests:
+		tabRequests = "[" + tabRequests + "]"
+	case SectionHistory:
+		tabHistory = "[" + tabHistory + "]"
+	case SectionRuns:
+		tabRuns = "[" + tabRuns + "]"
+	}
+	tabs := fmt.Sprintf("%s  %s  %s", tabRequests, tabHistory, tabRuns)
+
+	var content string
+	if m.ActiveSection == SectionHistory {
+		content = m.viewHistory(width, height-2)
+	} else if m.ActiveSection == SectionRuns {
+		content = m.viewRuns(width, height-2)
+	} else {
+		var items []string
+		for i, req := range m.Requests {
+			line := truncate(fmt.Sprintf("%s %s", req.Method, req.Title()), width-4)
+
+			if i == m.SelectedReqIdx {
+				items = append(items, selectedItemStyle.Render("> "+line))
+			} else {
+				items = append(items, itemStyle.Render("  "+line))
+			}
+		}
+
+		content = strings.Join(items, "\n")
+		if content == "" {
+			content = "No requests. Press 'n' to create."
+		}
+		if m.IsImporting {
+			content = m.ImportInput.View() + "\n\n" + content
+		}
+	}
+
+	return style.
+		Width(width).
+		Height(height).
+		Render(tabs + "\n\n" + content)
+}
+
+func (m Model) viewHistory(width, height int) string {
+	var sb strings.Builder
+	if m.IsEditing || m.HistoryFilter.Value() != "" {
+		sb.WriteString(m.HistoryFilter.View() + "\n\n")
+		height -= 2
+	}
+
+	entries := m.filteredHistory()
+	if len(entries) == 0 {
+		if len(m.HistoryEntries) == 0 {
+			sb.WriteString("No history yet. Run a request with 'r'.")
+		} else {
+			sb.WriteString("No matches.")
+		}
+		return sb.String()
+	}
+
+	// Only render the window of entries around the cursor
+	start, end := visibleRange(m.SelectedHistIdx, len(entries), height)
+
 	var items []string
-	for i, req := range m.Requests {
-		line := fmt.Sprintf("%s %s", req.Method, req.URL)
-		if len(line) > width-4 {
-			line = line[:width-4] + "..."
+	for i := start; i < end; i++ {
+		e := entries[i]
+		status := strconv.Itoa(e.StatusCode)
+		if e.Error != "" {
+			status = "ERR"
 		}
+		line := truncate(fmt.Sprintf("%s %s %s %s %s", e.Time.Format("15:04:05"), status, e.Request.Method,
+			e.Request.Title(), e.TimeTaken.Round(time.Millisecond)), width-4)
 
-		if i == m.SelectedReqIdx {
+		if i == m.SelectedHistIdx {
 			items = append(items, selectedItemStyle.Render("> "+line))
 		} else {
 			items = append(items, itemStyle.Render("  "+line))
 		}
 	}
+	sb.WriteString(strings.Join(items, "\n"))
+	return sb.String()
+}
 
-	content := strings.Join(items, "\n")
-	if content == "" {
-		content = "No requests. Press 'n' to create."
+// truncate shortens s to at most max characters (including the "..." marker).
+func truncate(s string, max int) string {
+	if max < 4 || len(s) <= max {
+		return s
 	}
+	return s[:max-3] + "..."
+}
 
-	return style.
-		Width(width).
-		Height(height).
-		Render(content)
+// visibleRange returns the [start, end) window of a list of total items
+// that fits in height lines and keeps the selected item in view.
+func visibleRange(selected, total, height int) (int, int) {
+	if height < 1 {
+		height = 1
+	}
+	if total <= height {
+		return 0, total
+	}
+	start := selected - height/2
+	if start < 0 {
+		start = 0
+	}
+	if start+height > total {
+		start = total - height
+	}
+	return start, start + height
 }
 
 func (m Model) viewEditor(width, height int) string {
@@ -102,84 +215,134 @@ func (m Model) viewEditor(width, height int) string {
 		return lStyle.Render(label) + "\n" + content + "\n"
 	}
 
+	nameView := renderField(FieldName, "Name", m.EditorInputs[2].View())
 	methodView := renderField(FieldMethod, "Method", m.EditorInputs[0].View())
 	urlView := renderField(FieldURL, "URL", m.EditorInputs[1].View())
 
 	// Tabs View
 	tabHeader := "Headers"
 	tabBody := "Body"
+	tabSettings := "Settings"
 	tabLoad := "Load"
 
 	if m.ActiveEditorTab == TabHeaders {
 		tabHeader = "[" + tabHeader + "]"
 	} else if m.ActiveEditorTab == TabBody {
 		tabBody = "[" + tabBody + "]"
+	} else if m.ActiveEditorTab == TabSettings {
+		tabSettings = "[" + tabSettings + "]"
 	} else if m.ActiveEditorTab == TabLoad {
 		tabLoad = "[" + tabLoad + "]"
 	}
-	tabsContent := fmt.Sprintf("%s  %s  %s", tabHeader, tabBody, tabLoad)
+	tabsContent := fmt.Sprintf("%s  %s  %s  %s", tabHeader, tabBody, tabSettings, tabLoad)
 	tabsView := renderField(FieldTabs, "", tabsContent) // Empty label for tabs
+	header := nameView + "\n" + methodView + "\n" + urlView + "\n" + tabsView + "\n"
 
 	// Content View
 	var contentView string
 	if m.ActiveEditorTab == TabBody {
-		contentView = renderField(FieldContent, "Body Content", m.EditorBody.View())
+		contentView = m.viewBodyTab()
 	} else if m.ActiveEditorTab == TabHeaders {
-		// Headers List
-		var sb strings.Builder
-		sb.WriteString(labelStyle.Render("Headers List (n: new, d: del)") + "\n")
-
-		for i, pair := range m.HeaderInputs {
-			// Determine styles for Key vs Value
-			kStyle := lipgloss.NewStyle()
-			vStyle := lipgloss.NewStyle()
-
-			if m.ActivePane == PaneEditor && m.FocusedField == FieldContent && i == m.FocusedHeaderIdx {
-				if m.FocusedHeaderKey {
-					kStyle = activeLabelStyle // Highlight Key
-				} else {
-					vStyle = activeLabelStyle // Highlight Value
-				}
-				sb.WriteString("> ")
-			} else {
-				sb.WriteString("  ")
+		contentView = m.viewPairs("Headers List (n: new, d: del)", m.HeaderInputs)
+	} else if fields := m.formFields(); len(fields) > 0 {
+		// Settings / Load Config
+		var rows []string
+		for i, f := range fields {
+			lStyle := labelStyle
+			// If focused on Content, highlight the sub-input under the cursor
+			if m.FocusedField == FieldContent && i == m.FocusedHeaderIdx {
+				lStyle = activeLabelStyle
 			}
+			rows = append(rows, lStyle.Render(f.Label)+"\n"+f.Input.View())
+		}
+		// Blank lines between the fields only while they all fit
+		sep := "\n\n"
+		if lipgloss.Height(header)+3*len(rows) > height {
+			sep = "\n"
+		}
+		contentView = strings.Join(rows, sep)
+	}
 
-			// Adjust width of inputs slightly for the list
-			pair.Key.Width = 15
-			pair.Value.Width = 20
+	return style.
+		Width(width).
+		Height(height).
+		Render(header + contentView)
+}
 
-			sb.WriteString(kStyle.Render(pair.Key.View()))
-			sb.WriteString(" : ")
-			sb.WriteString(vStyle.Render(pair.Value.View()))
-			sb.WriteString("\n")
+// viewBodyTab renders the Body tab: the mode, then the rows of that mode.
+func (m Model) viewBodyTab() string {
+	// The row under the cursor is highlighted once the content is focused
+	rowStyle := func(i int) lipgloss.Style {
+		if m.ActivePane == PaneEditor && m.FocusedField == FieldContent && i == m.FocusedHeaderIdx {
+			return activeLabelStyle
 		}
-		contentView = sb.String()
-	} else if m.ActiveEditorTab == TabLoad {
-		// Load Config
-		cStyle := labelStyle
-		dStyle := labelStyle
-		// If focused on Content, determine which sub-input is focused based on index
-		if m.FocusedField == FieldContent {
-			if m.FocusedHeaderIdx == 0 {
-				cStyle = activeLabelStyle
-			}
-			if m.FocusedHeaderIdx == 1 {
-				dStyle = activeLabelStyle
-			}
+		return labelStyle
+	}
+
+	modes := make([]string, len(model.BodyModes))
+	for i, mode := range model.BodyModes {
+		modes[i] = mode.String()
+		if mode == m.BodyMode {
+			modes[i] = "[" + modes[i] + "]"
 		}
+	}
+	view := labelStyle.Render("Mode (m: next)") + "\n" + strings.Join(modes, "  ") + "\n\n"
 
-		contentView = fmt.Sprintf("%s\n%s\n\n%s\n%s",
-			cStyle.Render("Concurrency"), m.LoadConfig.Concurrency.View(),
-			dStyle.Render("Duration"), m.LoadConfig.Duration.View())
+	switch m.BodyMode {
+	case model.BodyJSON:
+		label := "Body Content"
+		if v := m.EditorBody.Value(); strings.TrimSpace(v) != "" && !json.Valid([]byte(v)) {
+			label += " (invalid JSON)"
+		}
+		view += rowStyle(0).Render(label) + "\n" + m.EditorBody.View()
+	case model.BodyURLEncoded:
+		view += m.viewPairs("Fields (n: new, d: del)", m.FormInputs)
+	case model.BodyMultipart:
+		view += m.viewPairs("Fields (n: new, d: del; value @path attaches a file)", m.FormInputs)
+	case model.BodyBinary:
+		// The placeholder shows the content type sent when none is given
+		contentType := m.BodyContentType
+		contentType.Placeholder = "application/octet-stream"
+		view += rowStyle(0).Render("File") + "\n" + m.BodyFile.View() + "\n\n" +
+			rowStyle(1).Render("Content Type") + "\n" + contentType.View()
+	default:
+		view += rowStyle(0).Render("Content Type") + "\n" + m.BodyContentType.View() + "\n\n" +
+			rowStyle(1).Render("Body Content") + "\n" + m.EditorBody.View()
 	}
+	return view
+}
 
-	content := methodView + "\n" + urlView + "\n" + tabsView + "\n" + contentView
+// viewPairs renders a key/value list such as the headers, marking the row under the cursor.
+func (m Model) viewPairs(title string, pairs []InputPair) string {
+	var sb strings.Builder
+	sb.WriteString(labelStyle.Render(title) + "\n")
 
-	return style.
-		Width(width).
-		Height(height).
-		Render(content)
+	for i, pair := range pairs {
+		// Determine styles for Key vs Value
+		kStyle := lipgloss.NewStyle()
+		vStyle := lipgloss.NewStyle()
+
+		if m.ActivePane == PaneEditor && m.FocusedField == FieldContent && i == m.FocusedHeaderIdx {
+			if m.FocusedHeaderKey {
+				kStyle = activeLabelStyle // Highlight Key
+			} else {
+				vStyle = activeLabelStyle // Highlight Value
+			}
+			sb.WriteString("> ")
+		} else {
+			sb.WriteString("  ")
+		}
+
+		// Adjust width of inputs slightly for the list
+		pair.Key.Width = 15
+		pair.Value.Width = 20
+
+		sb.WriteString(kStyle.Render(pair.Key.View()))
+		sb.WriteString(" : ")
+		sb.WriteString(vStyle.Render(pair.Value.View()))
+		sb.WriteString("\n")
+	}
+	return sb.String()
 }
 
 func (m Model) viewResponse(width, height int) string {
@@ -188,29 +351,29 @@ func (m Model) viewResponse(width, height int) string {
 		style = focusedStyle
 	}
 
-	// Check if Load Mode (either running, or we have stats to show)
-	showDashboard := m.LoadState.IsRunning
-	if !showDashboard && m.LoadState.Stats != nil && m.LoadState.Stats.TotalRequests > 0 {
-		showDashboard = true
-	}
-
 	var content string
-	if showDashboard {
+	if m.ShowRun {
+		content = m.viewSelectedRun(width, height)
+	} else if m.showDashboard() {
 		content = m.viewDashboard(width, height)
 	} else {
 		content = "No response yet.\nPress 'r' to run."
-		if m.Response != nil {
-			if m.Response.Error != nil {
+		if m.IsRunningRequest {
+			content = m.Spinner.View() + " Running request...\nPress 'x' to cancel."
+		} else if m.Response != nil {
+			if errors.Is(m.Response.Error, context.Canceled) {
+				content = fmt.Sprintf("Request cancelled after %s.", m.Response.TimeTaken.Round(time.Millisecond))
+			} else if m.Response.Error != nil {
 				content = fmt.Sprintf("Error:\n%v", m.Response.Error)
 			} else {
-				// Basic truncating for large bodies logic could go here
-				body := m.Response.Body
-				if len(body) > 2000 {
-					body = body[:2000] + "\n... (truncated)"
+				// The filter bar takes the place of the blank line below the tabs
+				filterBar := ""
+				if m.ActiveResponseView == ResponseBody && (m.IsFilteringBody || m.ResponseFilter.Value() != "") {
+					filterBar = m.ResponseFilter.View()
 				}
-
-				content = fmt.Sprintf("Status: %d\nTime: %s\n\nBody:\n%s",
-					m.Response.StatusCode, m.Response.TimeTaken, body)
+				content = fmt.Sprintf("Status: %d\nTime: %s\nSize: %d B\n\n%s\n%s\n%s",
+					m.Response.StatusCode, m.Response.TimeTaken.Round(time.Microsecond), m.Response.SizeDownload,
+					m.viewResponseTabs(), filterBar, m.ResponseViewport.View())
 			}
 		}
 	}
@@ -221,15 +384,73 @@ func (m Model) viewResponse(width, height int) string {
 		Render(content)
 }
 
+// showDashboard reports whether the response pane shows the load dashboard:
+// while a test runs, or once one has results.
+func (m Model) showDashboard() bool {
+	s := m.LoadState.Stats
+	return m.LoadState.IsRunning || (s != nil && (s.TotalRequests > 0 || s.CutOff > 0))
+}
+
+// dashboardGraph is one of the per-second graphs of the load dashboard.
+type dashboardGraph struct {
+	Name    string // Tab label
+	Caption string
+	Legends []string // One per series, if more than one
+	Series  []func(load.WindowStats) float64
+}
+
+var dashboardGraphs = []dashboardGraph{
+	{
+		Name:    "Latency",
+		Caption: "Latency (ms)",
+		Legends: []string{"p50", "p99"},
+		Series: []func(load.WindowStats) float64{
+			func(w load.WindowStats) float64 { return float64(w.P50.Microseconds()) / 1000 },
+			func(w load.WindowStats) float64 { return float64(w.P99.Microseconds()) / 1000 },
+		},
+	},
+	{
+		Name:    "RPS",
+		Caption: "Requests/s",
+		Series:  []func(load.WindowStats) float64{load.WindowStats.RPS},
+	},
+	{
+		Name:    "Errors",
+		Caption: "Error rate (%)",
+		Series:  []func(load.WindowStats) float64{func(w load.WindowStats) float64 { return 100 * w.ErrorRate() }},
+	},
+	{
+		Name:    "Workers",
+		Caption: "Requests in flight",
+		Series:  []func(load.WindowStats) float64{func(w load.WindowStats) float64 { return float64(w.InFlight) }},
+	},
+}
+
 func (m Model) viewDashboard(width, height int) string {
 	s := m.LoadState.Stats
 	if s == nil {
 		return "Initializing..."
 	}
 
+	state := "Done"
+	if m.LoadState.IsRunning {
+		state = m.Spinner.View() + " Running (x to abort)"
+	} else if s.AbortedBy != "" {
+		state = "Aborted: " + s.AbortedBy + " failed"
+	} else if s.Aborted {
+		state = "Aborted"
+	} else if s.DataExhausted {
+		state = "Done: data file ran out"
+	}
+
 	// 1. Stats Column
-	stats := fmt.Sprintf("Valid Reqs: %d\nElapsed: %s\nAvg Latency: %s\nMax Latency: %s\n\nStatus Codes:\n",
-		s.TotalRequests, s.ElapsedTime.Round(time.Millisecond), s.AvgLatency.Round(time.Millisecond), s.MaxLatency.Round(time.Millisecond))
+	stats := fmt.Sprintf("State: %s\nValid Reqs: %d\nCut Off: %d\nElapsed: %s\nAvg Latency: %s\nStd Dev: %s\nMax Latency: %s\n\nPercentiles:\n",
+		state, s.TotalRequests, s.CutOff, s.ElapsedTime.Round(time.Millisecond), s.AvgLatency.Round(time.Millisecond),
+		s.Latency.StdDev().Round(time.Millisecond), s.MaxLatency.Round(time.Millisecond))
+	for _, p := range []float64{50, 90, 95, 99, 99.9} {
+		stats += fmt.Sprintf("  p%g: %s\n", p, s.Percentile(p).Round(100*time.Microsecond))
+	}
+	stats += "\nStatus Codes:\n"
 
 	for code, count := range s.StatusCodes {
 		pct := 0.0
@@ -238,30 +459,268 @@ func (m Model) viewDashboard(width, height int) string {
 		}
 		stats += fmt.Sprintf("  %d: %d (%.1f%%)\n", code, count, pct)
 	}
+	if s.TargetRPS > 0 {
+		stats += fmt.Sprintf("\nRPS: %.1f / %.1f target\nDropped: %d\nLate: %d\n", s.RPS(), s.TargetRPS, s.Dropped, s.Late)
+	}
+	if len(s.Stages) > 0 {
+		stats += fmt.Sprintf("\nStage: %d/%d\n", s.Stage+1, len(s.Stages))
+		if s.Profile.Rate {
+			stats += fmt.Sprintf("RPS: %.1f / %.1f target\nDropped: %d\nLate: %d\n", s.StageRPS(s.Stage), s.StageTarget, s.Dropped, s.Late)
+		} else {
+			stats += fmt.Sprintf("Workers: %.0f target\n", math.Ceil(s.StageTarget))
+		}
+		stats += fmt.Sprintf("In Flight: %d\n", s.InFlight)
+	}
 
-	// 2. Graph
-	// Prepare data
-	data := s.LatencyPoints
-	// Cap points to fit screen width roughly? Asciigraph handles auto-scaling but too many points might be slow/messy.
-	// If > width, take tail
-	plotData := data
-	graphWidth := width - 30
-	if graphWidth < 0 {
+	// 2. Graph of the last per-second windows that fit
+	graphWidth := width - 36 // Leaves room for the axis labels and the stats column
+	if graphWidth < 10 {
 		graphWidth = 10
 	}
+	windows := s.Windows()
+	if len(windows) > graphWidth {
+		windows = windows[len(windows)-graphWidth:]
+	}
+	graph := m.viewGraph(windows, graphWidth, height/2-1) // Leaves room for the tabs
+
+	// The tabs go above both columns; in the graph's they'd widen it
+	dashboard := m.viewGraphTabs() + "\n" + lipgloss.JoinHorizontal(lipgloss.Top, graph, "\n"+stats)
+
+	// Errors, stages and thresholds go below, where there's room for the details
+	var sb strings.Builder
+	rates := make([]float64, len(windows))
+	for i, w := range windows {
+		rates[i] = w.ErrorRate()
+	}
+	failed := 0.0
+	if s.TotalRequests > 0 {
+		failed = 100 * float64(s.Failures()) / float64(s.TotalRequests)
+	}
+	sb.WriteString(fmt.Sprintf("\nError rate %s %.1f%% overall", sparkline(rates), failed))
+	for _, class := range load.ErrorClasses {
+		if c, ok := s.ErrorClasses[class]; ok {
+			line := fmt.Sprintf("  %s: %d, first at %s: %s", class, c.Count, c.FirstSeen.Round(time.Millisecond), c.Sample)
+			sb.WriteString("\n" + thresholdFailStyle.Render(truncate(line, width-2)))
+		}
+	}
+	if !m.LoadState.IsRunning {
+		sb.WriteString("\n" + viewWindowSummary(s.Windows()))
+	}
+	if len(s.Steps) > 0 {
+		sb.WriteString("\nRequests:")
+	}
+	for _, st := range s.Steps {
+		line := fmt.Sprintf("  %s: %d reqs, %d failures, %s", st.Name, st.Requests, st.Failures, formatCounts(st.StatusCodes))
+		sb.WriteString("\n" + truncate(line, width-2))
+		sb.WriteString(fmt.Sprintf("\n    p50 %s  p95 %s  p99 %s", st.Latency.Percentile(50).Round(100*time.Microsecond),
+			st.Latency.Percentile(95).Round(100*time.Microsecond), st.Latency.Percentile(99).Round(100*time.Microsecond)))
+	}
+	if len(s.Stages) > 0 {
+		sb.WriteString("\nStages:")
+	}
+	for i, st := range s.Stages {
+		line := fmt.Sprintf("  %d. %s", i+1, s.Profile.Describe(i))
+		if st.Requests > 0 {
+			line += fmt.Sprintf(": %d reqs, %.1f rps, p95 %s, %d errors",
+				st.Requests, s.StageRPS(i), s.StagePercentile(i, 95).Round(time.Millisecond), st.Errors)
+		}
+		if i == s.Stage && m.LoadState.IsRunning {
+			line = currentStageStyle.Render(line + " ◀")
+		}
+		sb.WriteString("\n" + line)
+	}
+	if len(s.Thresholds) > 0 {
+		sb.WriteString("\nThresholds:")
+	}
+	for _, t := range s.Thresholds {
+		if t.Pass {
+			sb.WriteString("\n" + thresholdPassStyle.Render(fmt.Sprintf("  ✓ %s (%s)", t.Threshold, t.FormatActual())))
+		} else {
+			sb.WriteString("\n" + thresholdFailStyle.Render(fmt.Sprintf("  ✗ %s (%s)", t.Threshold, t.FormatActual())))
+		}
+	}
+	return dashboard + "\n" + sb.String()
+}
+
+// viewGraph plots the selected dashboard graph over windows.
+func (m Model) viewGraph(windows []load.WindowStats, width, height int) string {
+	g := dashboardGraphs[m.LoadState.Graph]
+	data := make([][]float64, len(g.Series))
+	for i, value := range g.Series {
+		for _, w := range windows {
+			data[i] = append(data[i], value(w))
+		}
+		if len(data[i]) == 0 {
+			data[i] = []float64{0} // Nothing to plot before the first second
+		}
+	}
+	options := []asciigraph.Option{
+		asciigraph.Height(height),
+		asciigraph.Width(width),
+		asciigraph.Caption(g.Caption),
+	}
+	if len(g.Legends) > 0 {
+		options = append(options,
+			asciigraph.SeriesColors(asciigraph.Green, asciigraph.Red),
+			asciigraph.SeriesLegends(g.Legends...))
+	}
+	return asciigraph.PlotMany(data, options...)
+}
+
+// viewGraphTabs shows which dashboard graph is selected.
+func (m Model) viewGraphTabs() string {
+	tabs := make([]string, len(dashboardGraphs))
+	for i, g := range dashboardGraphs {
+		tabs[i] = g.Name
+		if i == m.LoadState.Graph {
+			tabs[i] = "[" + g.Name + "]"
+		}
+	}
+	return strings.Join(tabs, "  ") + "  (g: next)"
+}
+
+// viewWindowSummary tabulates the spread of the per-second metrics of a finished test.
+func viewWindowSummary(windows []load.WindowStats) string {
+	if len(windows) == 0 {
+		return ""
+	}
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	rows := []struct {
+		label string
+		value func(load.WindowStats) float64
+	}{
+		{"Requests/s", load.WindowStats.RPS},
+		{"Error rate %", func(w load.WindowStats) float64 { return 100 * w.ErrorRate() }},
+		{"p50 (ms)", func(w load.WindowStats) float64 { return ms(w.P50) }},
+		{"p99 (ms)", func(w load.WindowStats) float64 { return ms(w.P99) }},
+		{"In flight", func(w load.WindowStats) float64 { return float64(w.InFlight) }},
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n%-18s %10s %10s %10s", fmt.Sprintf("Per second (%ds)", len(windows)), "min", "mean", "max"))
+	for _, row := range rows {
+		r := load.SummarizeWindows(windows, row.value)
+		sb.WriteString(fmt.Sprintf("\n  %-16s %10.1f %10.1f %10.1f", row.label, r.Min, r.Mean, r.Max))
+	}
+	return sb.String()
+}
+
+// sparkBlocks are the levels of a sparkline, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values (between 0 and 1) as a one-line bar chart scaled to the largest.
+func sparkline(values []float64) string {
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if peak > 0 {
+			level = int(math.Round(v / peak * float64(len(sparkBlocks)-1)))
+		}
+		line[i] = sparkBlocks[level]
+	}
+	return string(line)
+}
+
+func (m Model) viewResponseTabs() string {
+	tabBody := "Body"
+	tabHeaders := fmt.Sprintf("Headers (%d)", len(m.Response.Headers))
+	tabTiming := "Timing"
+
+	if m.ActiveResponseView == ResponseBody {
+		tabBody = "[" + tabBody + "]"
+	} else if m.ActiveResponseView == ResponseHeaders {
+		tabHeaders = "[" + tabHeaders + "]"
+	} else if m.ActiveResponseView == ResponseTiming {
+		tabTiming = "[" + tabTiming + "]"
+	}
+	tabs := fmt.Sprintf("%s  %s  %s", tabBody, tabHeaders, tabTiming)
+	if m.ActiveResponseView == ResponseBody && m.RawBody {
+		tabs += labelStyle.Render("  raw")
+	}
+	if !m.ResponseViewport.AtTop() || !m.ResponseViewport.AtBottom() {
+		tabs += labelStyle.Render(fmt.Sprintf("  %3.f%%", m.ResponseViewport.ScrollPercent()*100))
+	}
+	return tabs
+}
+
+func viewHeaders(headers http.Header) string {
+	if len(headers) == 0 {
+		return labelStyle.Render("No headers.")
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Repeated headers (e.g. Set-Cookie) get one line per value
+	var sb strings.Builder
+	for _, k := range keys {
+		for _, v := range headers[k] {
+			sb.WriteString(activeLabelStyle.Render(k+":") + " " + v + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// viewTiming renders the request phases as a waterfall, each bar offset by when the phase started.
+func viewTiming(resp model.Response, width int) string {
+	t := resp.Timing
+	if t.Total <= 0 {
+		return labelStyle.Render("No timing data.")
+	}
+
+	// TLS is skipped for plain HTTP, so the server phase starts after whichever handshake finished last
+	handshakeDone := t.Connect
+	if t.AppConnect > handshakeDone {
+		handshakeDone = t.AppConnect
+	}
 
-	if len(data) > graphWidth {
-		plotData = data[len(data)-graphWidth:]
+	phases := []struct {
+		label      string
+		start, end time.Duration
+	}{
+		{"DNS", 0, t.NameLookup},
+		{"Connect", t.NameLookup, t.Connect},
+		{"TLS", t.Connect, t.AppConnect},
+		{"TTFB", handshakeDone, t.StartTransfer},
+		{"Download", t.StartTransfer, t.Total},
 	}
-	if len(plotData) == 0 {
-		plotData = []float64{0}
+
+	barWidth := width - 22
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	scale := func(d time.Duration) int {
+		return int(float64(d) / float64(t.Total) * float64(barWidth))
 	}
 
-	graph := asciigraph.Plot(plotData,
-		asciigraph.Height(height/2),
-		asciigraph.Width(graphWidth),
-		asciigraph.Caption("Latency (ms)"),
-	)
+	var sb strings.Builder
+	for _, p := range phases {
+		dur := p.end - p.start
+		if dur < 0 || p.end == 0 {
+			dur = 0
+		}
+
+		offset := scale(p.start)
+		length := scale(p.start+dur) - offset
+		if length == 0 && dur > 0 {
+			length = 1
+		}
+		if offset+length > barWidth {
+			offset = barWidth - length
+		}
+
+		bar := strings.Repeat(" ", offset) + activeLabelStyle.Render(strings.Repeat("█", length))
+		sb.WriteString(fmt.Sprintf("%-9s%9s  %s\n", p.label, dur.Round(time.Microsecond), bar))
+	}
+	sb.WriteString(fmt.Sprintf("%-9s%9s\n\n", "Total", t.Total.Round(time.Microsecond)))
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, graph, "\n\n"+stats)
+	sb.WriteString(fmt.Sprintf("Remote IP: %s\nHTTP:      %s\nRedirects: %d", resp.RemoteIP, resp.HTTPVersion, resp.NumRedirects))
+	return sb.String()
 }
@@ -0,0 +1,150 @@
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"lazycurl/internal/env"
+	"lazycurl/internal/model"
+	"os"
+	"path/filepath"
+)
+
+// Files inside a workspace.
+const (
+	RequestsFile     = "requests.jsonl"    // Request collection, one JSON request per line
+	EnvironmentsFile = "environments.json" // Environments and the active one
+)
+
+// Workspace is a directory holding a request collection and its environments.
+type Workspace struct {
+	Dir string
+}
+
+// DefaultDir returns the workspace used when no --workspace flag is given.
+func DefaultDir() (string, error) {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %v", err)
+	}
+	return filepath.Join(config, "lazycurl"), nil
+}
+
+// Open opens the workspace at dir, creating the directory if needed.
+func Open(dir string) (*Workspace, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create workspace %s: %v", dir, err)
+	}
+	return &Workspace{Dir: dir}, nil
+}
+
+// Path returns the path of a file inside the workspace.
+func (w *Workspace) Path(name string) string {
+	return filepath.Join(w.Dir, name)
+}
+
+// LoadRequests reads the collection in its saved order.
+// A missing file is an empty collection; requests saved without an ID get one.
+func (w *Workspace) LoadRequests() ([]model.Request, error) {
+	data, err := os.ReadFile(w.Path(RequestsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read requests: %v", err)
+	}
+
+	var reqs []model.Request
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024) // Bodies can be large
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var req model.Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", RequestsFile, line, err)
+		}
+		if req.ID == "" {
+			req.ID = model.NewID()
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		reqs = append(reqs, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read requests: %v", err)
+	}
+	return reqs, nil
+}
+
+// SaveRequests replaces the collection on disk.
+func (w *Workspace) SaveRequests(reqs []model.Request) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Keep bodies like "a=1&b=2" readable in the file
+	for _, req := range reqs {
+		if err := enc.Encode(req); err != nil {
+			return fmt.Errorf("failed to encode request %s: %v", req.ID, err)
+		}
+	}
+	return WriteFileAtomic(w.Path(RequestsFile), buf.Bytes())
+}
+
+// WriteFileAtomic writes data to a temp file next to path and renames it into place,
+// so a crash mid-write leaves either the old or the new file, never a truncated one.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %v", filepath.Base(path), err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save %s: %v", filepath.Base(path), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save %s: %v", filepath.Base(path), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save %s: %v", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// LoadEnvironments reads the workspace environments. A missing file is an empty set.
+func (w *Workspace) LoadEnvironments() (*env.Set, error) {
+	set := &env.Set{}
+	data, err := os.ReadFile(w.Path(EnvironmentsFile))
+	if os.IsNotExist(err) {
+		return set, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environments: %v", err)
+	}
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("%s: %v", EnvironmentsFile, err)
+	}
+	for i := range set.Environments {
+		if set.Environments[i].Vars == nil {
+			set.Environments[i].Vars = make(map[string]string)
+		}
+	}
+	return set, nil
+}
+
+// SaveEnvironments replaces the workspace environments on disk.
+func (w *Workspace) SaveEnvironments(set *env.Set) error {
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode environments: %v", err)
+	}
+	return WriteFileAtomic(w.Path(EnvironmentsFile), append(data, '\n'))
+}
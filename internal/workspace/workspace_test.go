@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.json")
+
+	for _, data := range []string{"first\n", "second\n"} {
+		if err := WriteFileAtomic(path, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != data {
+			t.Errorf("file = %q, want %q", got, data)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the target (temp file left behind?)", len(entries))
+	}
+}
+
+func TestWriteFileAtomicMissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "file.json")
+	if err := WriteFileAtomic(path, []byte("x")); err == nil {
+		t.Error("WriteFileAtomic into a missing directory succeeded")
+	}
+}
+
+func TestEnvironmentsRoundTrip(t *testing.T) {
+	ws, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := ws.LoadEnvironments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Environments) != 0 || set.Active != "" {
+		t.Fatalf("missing file loaded as %+v, want an empty set", set)
+	}
+
+	set.Upsert("local").Vars["host"] = "localhost:8080"
+	set.Upsert("prod").Vars["host"] = "api.example.com"
+	set.Active = "prod"
+	if err := ws.SaveEnvironments(set); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ws.LoadEnvironments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, set) {
+		t.Errorf("loaded %+v, want %+v", got, set)
+	}
+}
+
+func TestLoadEnvironmentsNilVars(t *testing.T) {
+	ws, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := `{"active": "ci", "environments": [{"name": "ci"}]}`
+	if err := os.WriteFile(ws.Path(EnvironmentsFile), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := ws.LoadEnvironments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars := set.ActiveVars(); vars == nil {
+		t.Error("environment saved without vars loaded with nil Vars")
+	} else {
+		vars["k"] = "v" // Must not panic
+	}
+}
+
+func TestLoadEnvironmentsInvalid(t *testing.T) {
+	ws, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ws.Path(EnvironmentsFile), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.LoadEnvironments(); err == nil {
+		t.Error("invalid environments.json loaded without error")
+	}
+}